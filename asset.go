@@ -0,0 +1,212 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"DNA/core/asset"
+	"DNA/core/transaction"
+	"DNA/core/transaction/payload"
+	"DNA/crypto"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNotAsset is returned by GetAsset when assetId is not the hash of a RegisterAsset transaction
+var ErrNotAsset = errors.New("not a RegisterAsset transaction")
+
+// ASSET_SCAN_BATCH is the count of blocks got in one batch when searching the register height
+const ASSET_SCAN_BATCH = 20
+
+// AssetInfo is the definition of an asset, from its RegisterAsset transaction, and the height
+// of the block of the transaction
+type AssetInfo struct {
+	AssetId        Uint256
+	Asset          *asset.Asset
+	Amount         Fixed64
+	Issuer         *crypto.PubKey
+	Controller     Uint160
+	RegisterHeight uint32
+}
+
+// assetBlock is the block of a RegisterAsset transaction, the hash is checked to find a rollback
+type assetBlock struct {
+	height uint32
+	hash   Uint256
+}
+
+// blockTxHashes is the part of BlockInfo used to search a transaction, without parsing the block
+type blockTxHashes struct {
+	Transactions []struct {
+		Hash string
+	}
+}
+
+func (this *DnaClient) GetAsset(assetId Uint256) (*AssetInfo, error) {
+	return this.GetAssetContext(context.Background(), assetId)
+}
+
+// GetAssetContext return the asset registered by transaction assetId and its register height.
+// Node has no index from transaction to block, the blocks are scanned down from the top, use
+// GetAssetFromHeight if the lowest height the asset can be registered at is known.
+func (this *DnaClient) GetAssetContext(ctx context.Context, assetId Uint256) (*AssetInfo, error) {
+	return this.GetAssetFromHeightContext(ctx, assetId, 0)
+}
+
+func (this *DnaClient) GetAssetFromHeight(assetId Uint256, minHeight uint32) (*AssetInfo, error) {
+	return this.GetAssetFromHeightContext(context.Background(), assetId, minHeight)
+}
+
+// GetAssetFromHeightContext is GetAssetContext scanning the blocks down to minHeight only. The asset
+// and its block are cached by client, the cached block is checked to be still in chain.
+func (this *DnaClient) GetAssetFromHeightContext(ctx context.Context, assetId Uint256, minHeight uint32) (*AssetInfo, error) {
+	info, err := this.getAssetInfo(ctx, assetId)
+	if err != nil {
+		return nil, err
+	}
+	block, err := this.getAssetBlock(ctx, assetId, minHeight)
+	if err != nil {
+		return nil, err
+	}
+	result := *info
+	result.RegisterHeight = block.height
+	return &result, nil
+}
+
+func (this *DnaClient) getAssetInfo(ctx context.Context, assetId Uint256) (*AssetInfo, error) {
+	this.assetLock.RLock()
+	info, ok := this.assets[assetId]
+	this.assetLock.RUnlock()
+	if ok {
+		return info, nil
+	}
+
+	tx, err := this.GetTransactionContext(ctx, assetId)
+	if err != nil {
+		return nil, fmt.Errorf("GetTransaction error:%w", err)
+	}
+	regAsset, ok := tx.Payload.(*payload.RegisterAsset)
+	if tx.TxType != transaction.RegisterAsset || !ok {
+		return nil, fmt.Errorf("%w TxType:%x", ErrNotAsset, tx.TxType)
+	}
+	info = &AssetInfo{
+		AssetId:    assetId,
+		Asset:      regAsset.Asset,
+		Amount:     regAsset.Amount,
+		Issuer:     regAsset.Issuer,
+		Controller: regAsset.Controller,
+	}
+
+	this.assetLock.Lock()
+	this.assets[assetId] = info
+	this.assetLock.Unlock()
+	return info, nil
+}
+
+func (this *DnaClient) getAssetBlock(ctx context.Context, assetId Uint256, minHeight uint32) (*assetBlock, error) {
+	this.assetLock.RLock()
+	block, ok := this.assetBlocks[assetId]
+	this.assetLock.RUnlock()
+	if ok {
+		hash, err := this.GetBlockHashContext(ctx, block.height)
+		if err != nil && !errors.Is(err, ErrUnknownBlock) {
+			return nil, fmt.Errorf("GetBlockHash error:%w", err)
+		}
+		if err == nil && hash == block.hash {
+			return block, nil
+		}
+		//the block is rolled back, search again
+		this.assetLock.Lock()
+		delete(this.assetBlocks, assetId)
+		this.assetLock.Unlock()
+	}
+
+	block, err := this.findAssetBlock(ctx, assetId, minHeight)
+	if err != nil {
+		return nil, err
+	}
+	this.assetLock.Lock()
+	this.assetBlocks[assetId] = block
+	this.assetLock.Unlock()
+	return block, nil
+}
+
+// findAssetBlock scan the blocks down from the top to minHeight for assetId. Only the transaction
+// hashes of the scanned blocks are decoded, the block found is got and checked as others.
+func (this *DnaClient) findAssetBlock(ctx context.Context, assetId Uint256, minHeight uint32) (*assetBlock, error) {
+	count, err := this.GetBlockCountContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockCount error:%w", err)
+	}
+	txHash := Uint256ToString(assetId)
+	for top := count; top > minHeight; {
+		elems := make([]*BatchElem, 0, ASSET_SCAN_BATCH)
+		for ; top > minHeight && len(elems) < ASSET_SCAN_BATCH; top-- {
+			elems = append(elems, &BatchElem{Method: DNA_RPC_GETBLOCK, Params: []interface{}{top - 1}})
+		}
+		err := this.BatchCallContext(ctx, elems)
+		if err != nil {
+			return nil, fmt.Errorf("BatchCall error:%w", err)
+		}
+		for _, elem := range elems {
+			height := elem.Params[0].(uint32)
+			if elem.Error != nil {
+				return nil, fmt.Errorf("GetBlock height:%d error:%w", height, elem.Error)
+			}
+			hashes := &blockTxHashes{}
+			err = json.Unmarshal(elem.Result, hashes)
+			if err != nil {
+				return nil, fmt.Errorf("json.Unmarshal BlockInfo:%s error:%w", elem.Result, err)
+			}
+			for _, tx := range hashes.Transactions {
+				if tx.Hash == txHash {
+					return this.checkAssetBlock(ctx, assetId, height)
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w asset:%x from height:%d", ErrTransactionNotFound, assetId.ToArray(), minHeight)
+}
+
+// checkAssetBlock get the block at height, and check it contains assetId
+func (this *DnaClient) checkAssetBlock(ctx context.Context, assetId Uint256, height uint32) (*assetBlock, error) {
+	block, err := this.GetBlockByHeightContext(ctx, height)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockByHeight height:%d error:%w", height, err)
+	}
+	if !containsTransaction(block, assetId) {
+		return nil, fmt.Errorf("%w asset:%x not in block height:%d", ErrIntegrity, assetId.ToArray(), height)
+	}
+	return &assetBlock{height: height, hash: block.Blockdata.Hash()}, nil
+}
+
+func (this *DnaClient) GetAssetDefinition(assetId Uint256) (*asset.Asset, error) {
+	return this.GetAssetDefinitionContext(context.Background(), assetId)
+}
+
+// GetAssetDefinitionContext return the asset stored by node, with DNA_API_GETASSET of the REST port.
+// The node without it, like the JSON-RPC port, answer ErrUnsupported or an error http status, and
+// the asset is got from the RegisterAsset transaction.
+func (this *DnaClient) GetAssetDefinitionContext(ctx context.Context, assetId Uint256) (*asset.Asset, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETASSET, []interface{}{Uint256ToString(assetId)})
+	statusErr := &HTTPStatusError{}
+	if errors.Is(err, ErrUnsupported) || errors.As(err, &statusErr) {
+		info, err := this.getAssetInfo(ctx, assetId)
+		if err != nil {
+			return nil, err
+		}
+		return info.Asset, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sendRpcRequest error:%w", err)
+	}
+	if isNullResult(data) {
+		return nil, fmt.Errorf("%w asset:%x", ErrNotAsset, assetId.ToArray())
+	}
+	assetDef := &asset.Asset{}
+	err = json.Unmarshal(data, assetDef)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal Asset:%s error:%w", data, err)
+	}
+	return assetDef, nil
+}
@@ -0,0 +1,169 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"DNA/core/ledger"
+	"DNA/core/transaction"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// BatchElem is one call of a batch request. Result and Error are set by BatchCall.
+type BatchElem struct {
+	Method string
+	Params []interface{}
+	Result []byte
+	Error  error
+}
+
+// BatchCall send all elems to node in one JSON-RPC array request, or one by one if
+// the transport is not a BatchTransport. The returned error is only for the failure of the whole batch, the error
+// of each call is set in BatchElem.Error.
+func (this *DnaClient) BatchCall(elems []*BatchElem) error {
+	return this.BatchCallContext(context.Background(), elems)
+}
+
+func (this *DnaClient) BatchCallContext(ctx context.Context, elems []*BatchElem) error {
+	if len(elems) == 0 {
+		return nil
+	}
+	batchTransport, ok := this.transport.(BatchTransport)
+	if !ok {
+		for _, elem := range elems {
+			elem.Result, elem.Error = this.sendRpcRequest(ctx, elem.Method, elem.Params)
+		}
+		return nil
+	}
+	reqs := make([]*RpcRequest, len(elems))
+	for i, elem := range elems {
+		reqs[i] = &RpcRequest{Method: elem.Method, Params: elem.Params, Qid: this.getQid()}
+	}
+	var ress []*RpcResponse
+	endpoint, _, err := this.roundTrip(ctx, "batch", func(endpoint *Endpoint) error {
+		var err error
+		ress, err = this.invokeBatch(ctx, batchTransport, endpoint.Address, reqs)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for i, elem := range elems {
+		elem.Result, elem.Error = this.handleRpcResponse(endpoint, elem.Method, ress[i])
+	}
+	return nil
+}
+
+// GetBlocksByHeight get blocks of heights in one batch request. errs[i] is the error of heights[i].
+func (this *DnaClient) GetBlocksByHeight(heights []uint32) ([]*ledger.Block, []error, error) {
+	return this.GetBlocksByHeightContext(context.Background(), heights)
+}
+
+func (this *DnaClient) GetBlocksByHeightContext(ctx context.Context, heights []uint32) ([]*ledger.Block, []error, error) {
+	elems := make([]*BatchElem, len(heights))
+	for i, height := range heights {
+		elems[i] = &BatchElem{Method: DNA_RPC_GETBLOCK, Params: []interface{}{height}}
+	}
+	err := this.BatchCallContext(ctx, elems)
+	if err != nil {
+		return nil, nil, err
+	}
+	blocks := make([]*ledger.Block, len(heights))
+	errs := make([]error, len(heights))
+	for i, elem := range elems {
+		if elem.Error != nil {
+			errs[i] = elem.Error
+			continue
+		}
+		if isNullResult(elem.Result) {
+			errs[i] = ErrUnknownBlock
+			continue
+		}
+		blockInfo := &BlockInfo{}
+		err = json.Unmarshal(elem.Result, blockInfo)
+		if err != nil {
+			errs[i] = fmt.Errorf("json.Unmarshal BlockInfo:%s error:%w", elem.Result, err)
+			continue
+		}
+		block, err := this.parseBlock(blockInfo)
+		if err == nil {
+			err = this.checkBlockHeight(block.Blockdata, heights[i])
+		}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		blocks[i] = block
+	}
+	return blocks, errs, nil
+}
+
+// GetBlockHashes get block hashes of heights in one batch request. errs[i] is the error of heights[i].
+func (this *DnaClient) GetBlockHashes(heights []uint32) ([]Uint256, []error, error) {
+	return this.GetBlockHashesContext(context.Background(), heights)
+}
+
+func (this *DnaClient) GetBlockHashesContext(ctx context.Context, heights []uint32) ([]Uint256, []error, error) {
+	elems := make([]*BatchElem, len(heights))
+	for i, height := range heights {
+		elems[i] = &BatchElem{Method: DNA_RPC_GETBLOCKHASH, Params: []interface{}{height}}
+	}
+	err := this.BatchCallContext(ctx, elems)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]Uint256, len(heights))
+	errs := make([]error, len(heights))
+	for i, elem := range elems {
+		if elem.Error != nil {
+			errs[i] = elem.Error
+			continue
+		}
+		hashes[i], errs[i] = ParseUint256FromString(string(elem.Result))
+	}
+	return hashes, errs, nil
+}
+
+// GetTransactions get transactions of txHashes in one batch request. errs[i] is the error of txHashes[i].
+func (this *DnaClient) GetTransactions(txHashes []Uint256) ([]*transaction.Transaction, []error, error) {
+	return this.GetTransactionsContext(context.Background(), txHashes)
+}
+
+func (this *DnaClient) GetTransactionsContext(ctx context.Context, txHashes []Uint256) ([]*transaction.Transaction, []error, error) {
+	elems := make([]*BatchElem, len(txHashes))
+	for i, txHash := range txHashes {
+		elems[i] = &BatchElem{Method: DNA_RPC_GETTRANSACTION, Params: []interface{}{Uint256ToString(txHash)}}
+	}
+	err := this.BatchCallContext(ctx, elems)
+	if err != nil {
+		return nil, nil, err
+	}
+	txs := make([]*transaction.Transaction, len(txHashes))
+	errs := make([]error, len(txHashes))
+	for i, elem := range elems {
+		if elem.Error != nil {
+			errs[i] = elem.Error
+			continue
+		}
+		if isNullResult(elem.Result) {
+			errs[i] = ErrUnknownTransaction
+			continue
+		}
+		txStr := &Transactions{}
+		err = json.Unmarshal(elem.Result, txStr)
+		if err != nil {
+			errs[i] = fmt.Errorf("json.Unmarshal Transactions:%s error:%w", elem.Result, err)
+			continue
+		}
+		tx, err := this.parseTransaction(txStr)
+		if err == nil {
+			err = this.checkTransactionHash(tx, txHashes[i])
+		}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		txs[i] = tx
+	}
+	return txs, errs, nil
+}
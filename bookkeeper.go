@@ -0,0 +1,204 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"DNA/core/ledger"
+	"DNA/crypto"
+	"fmt"
+)
+
+// The checks of HeaderVerifier, in BlockValidationError
+const (
+	BLOCK_CHECK_BOOKKEEPER = "bookkeeper"
+	BLOCK_CHECK_SIGNATURE  = "signature"
+)
+
+// Opcodes of the DNA vm used by the bookkeeper contracts
+const (
+	opPushBytes1    = 0x01
+	opPushBytes33   = 0x21
+	opPushBytes64   = 0x40
+	opPush1         = 0x51
+	opPush16        = 0x60
+	opCheckSig      = 0xAC
+	opCheckMultiSig = 0xAE
+)
+
+// HeaderVerifier verify a chain of block headers from a trusted checkpoint, without trusting the
+// node. Every header must be signed by the bookkeepers named by NextBookKeeper of the header before.
+type HeaderVerifier struct {
+	last *ledger.Blockdata
+}
+
+// NewHeaderVerifier return a HeaderVerifier following checkpoint, a header got from a trusted source
+func NewHeaderVerifier(checkpoint *ledger.Blockdata) (*HeaderVerifier, error) {
+	if checkpoint == nil {
+		return nil, fmt.Errorf("checkpoint is nil")
+	}
+	return &HeaderVerifier{last: checkpoint}, nil
+}
+
+// Last return the last verified header
+func (this *HeaderVerifier) Last() *ledger.Blockdata {
+	return this.last
+}
+
+// Verify check header is the next of the last verified header and is signed by its bookkeepers,
+// and keep it as the last on success. BlockValidationError is returned on failure.
+func (this *HeaderVerifier) Verify(header *ledger.Blockdata) error {
+	if header == nil {
+		return fmt.Errorf("%w: header is nil", ErrBlockValidation)
+	}
+	if this.last == nil {
+		return fmt.Errorf("%w: no checkpoint, use NewHeaderVerifier", ErrBlockValidation)
+	}
+	if header.Height != this.last.Height+1 {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_HEIGHT,
+			Detail: fmt.Sprintf("previous height:%d", this.last.Height),
+		}
+	}
+	lastHash := this.last.Hash()
+	if header.PrevBlockHash != lastHash {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_PREV_HASH,
+			Detail: fmt.Sprintf("PrevBlockHash:%x previous block hash:%x", header.PrevBlockHash.ToArray(), lastHash.ToArray()),
+		}
+	}
+	err := VerifyBookKeeperSignature(header, this.last.NextBookKeeper)
+	if err != nil {
+		return err
+	}
+	this.last = header
+	return nil
+}
+
+// VerifyBookKeeperSignature check the Program of header is the contract of bookKeeper, the
+// NextBookKeeper of the previous header, and its signatures of header are valid
+func VerifyBookKeeperSignature(header *ledger.Blockdata, bookKeeper Uint160) error {
+	program := header.Program
+	if program == nil {
+		return &BlockValidationError{Height: header.Height, Check: BLOCK_CHECK_BOOKKEEPER, Detail: "no program"}
+	}
+	codeHash, err := ToCodeHash(program.Code)
+	if err != nil {
+		return fmt.Errorf("ToCodeHash error:%w", err)
+	}
+	if codeHash != bookKeeper {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_BOOKKEEPER,
+			Detail: fmt.Sprintf("program hash:%x NextBookKeeper:%x", codeHash.ToArray(), bookKeeper.ToArray()),
+		}
+	}
+	m, pubKeys, err := parseBookKeeperContract(program.Code)
+	if err != nil {
+		return &BlockValidationError{Height: header.Height, Check: BLOCK_CHECK_BOOKKEEPER, Detail: err.Error()}
+	}
+	sigs, err := parseSignatures(program.Parameter)
+	if err != nil {
+		return &BlockValidationError{Height: header.Height, Check: BLOCK_CHECK_SIGNATURE, Detail: err.Error()}
+	}
+	if !verifyMultiSignature(header.GetMessage(), m, pubKeys, sigs) {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_SIGNATURE,
+			Detail: fmt.Sprintf("less than %d valid signatures of %d bookkeepers", m, len(pubKeys)),
+		}
+	}
+	return nil
+}
+
+// verifyMultiSignature check m of sigs are valid, sigs are in the order of pubKeys as CHECKMULTISIG
+func verifyMultiSignature(data []byte, m int, pubKeys []*crypto.PubKey, sigs [][]byte) bool {
+	if len(sigs) < m {
+		return false
+	}
+	i, j := 0, 0
+	for i < len(sigs) && j < len(pubKeys) && i < m {
+		if crypto.Verify(*pubKeys[j], data, sigs[i]) == nil {
+			i++
+		}
+		j++
+		if m-i > len(pubKeys)-j {
+			return false
+		}
+	}
+	return i >= m
+}
+
+// parseBookKeeperContract return m and the public keys of a multisig contract
+// (PUSH m, PUSHBYTES33 pubkey..., PUSH n, CHECKMULTISIG), or of a signature contract
+// (PUSHBYTES33 pubkey, CHECKSIG) as 1 of 1
+func parseBookKeeperContract(code []byte) (int, []*crypto.PubKey, error) {
+	if len(code) == 35 && code[0] == opPushBytes33 && code[34] == opCheckSig {
+		pubKey, err := crypto.DecodePoint(code[1:34])
+		if err != nil {
+			return 0, nil, fmt.Errorf("crypto.DecodePoint error:%w", err)
+		}
+		return 1, []*crypto.PubKey{pubKey}, nil
+	}
+	if len(code) == 0 || code[len(code)-1] != opCheckMultiSig {
+		return 0, nil, fmt.Errorf("program code is not a multisig contract")
+	}
+	m, pos, err := readScriptNumber(code, 0)
+	if err != nil {
+		return 0, nil, fmt.Errorf("read m error:%w", err)
+	}
+	pubKeys := make([]*crypto.PubKey, 0)
+	for pos < len(code) && code[pos] == opPushBytes33 {
+		if pos+34 > len(code) {
+			return 0, nil, fmt.Errorf("public key out of code")
+		}
+		pubKey, err := crypto.DecodePoint(code[pos+1 : pos+34])
+		if err != nil {
+			return 0, nil, fmt.Errorf("crypto.DecodePoint error:%w", err)
+		}
+		pubKeys = append(pubKeys, pubKey)
+		pos += 34
+	}
+	n, pos, err := readScriptNumber(code, pos)
+	if err != nil {
+		return 0, nil, fmt.Errorf("read n error:%w", err)
+	}
+	if pos != len(code)-1 {
+		return 0, nil, fmt.Errorf("unexpected data after n")
+	}
+	if n != len(pubKeys) || m < 1 || m > n {
+		return 0, nil, fmt.Errorf("invalid m:%d n:%d public keys:%d", m, n, len(pubKeys))
+	}
+	return m, pubKeys, nil
+}
+
+// readScriptNumber read a number pushed by PUSH1-PUSH16, or by PUSHBYTES as little endian
+func readScriptNumber(code []byte, pos int) (int, int, error) {
+	if pos >= len(code) {
+		return 0, pos, fmt.Errorf("number out of code")
+	}
+	op := code[pos]
+	if op >= opPush1 && op <= opPush16 {
+		return int(op-opPush1) + 1, pos + 1, nil
+	}
+	if op < opPushBytes1 || op > 4 || pos+1+int(op) > len(code) {
+		return 0, pos, fmt.Errorf("invalid number opcode:%x", op)
+	}
+	n := 0
+	for i := int(op); i > 0; i-- {
+		n = n<<8 | int(code[pos+i])
+	}
+	return n, pos + 1 + int(op), nil
+}
+
+// parseSignatures return the signatures of program parameter, each pushed by PUSHBYTES64
+func parseSignatures(parameter []byte) ([][]byte, error) {
+	sigs := make([][]byte, 0)
+	for pos := 0; pos < len(parameter); pos += 65 {
+		if parameter[pos] != opPushBytes64 || pos+65 > len(parameter) {
+			return nil, fmt.Errorf("invalid signature at:%d", pos)
+		}
+		sigs = append(sigs, parameter[pos+1:pos+65])
+	}
+	return sigs, nil
+}
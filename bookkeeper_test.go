@@ -0,0 +1,159 @@
+package dnasdk
+
+import (
+	"DNA/account"
+	. "DNA/common"
+	"DNA/core/contract"
+	"DNA/core/contract/program"
+	"DNA/core/ledger"
+	"DNA/crypto"
+	"errors"
+	"testing"
+)
+
+func newTestAccounts(t *testing.T, n int) []*account.Account {
+	accounts := make([]*account.Account, 0, n)
+	for i := 0; i < n; i++ {
+		acc, err := account.NewAccount()
+		if err != nil {
+			t.Fatalf("NewAccount error:%s", err)
+		}
+		accounts = append(accounts, acc)
+	}
+	return accounts
+}
+
+// contractOrder return accounts in the order of the public keys of the bookkeeper contract code,
+// the order CHECKMULTISIG expects the signatures in
+func contractOrder(t *testing.T, code []byte, accounts []*account.Account) []*account.Account {
+	_, pubKeys, err := parseBookKeeperContract(code)
+	if err != nil {
+		t.Fatalf("parseBookKeeperContract error:%s", err)
+	}
+	ordered := make([]*account.Account, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		for _, acc := range accounts {
+			if acc.PubKey().X.Cmp(pubKey.X) == 0 && acc.PubKey().Y.Cmp(pubKey.Y) == 0 {
+				ordered = append(ordered, acc)
+			}
+		}
+	}
+	if len(ordered) != len(accounts) {
+		t.Fatalf("contract public keys:%d accounts:%d", len(ordered), len(accounts))
+	}
+	return ordered
+}
+
+// signHeader set the program of header to code with the signatures of signers
+func signHeader(t *testing.T, header *ledger.Blockdata, code []byte, signers []*account.Account) {
+	parameter := make([]byte, 0, len(signers)*65)
+	for _, signer := range signers {
+		sig, err := crypto.Sign(signer.PrivKey(), header.GetMessage())
+		if err != nil {
+			t.Fatalf("crypto.Sign error:%s", err)
+		}
+		parameter = append(parameter, opPushBytes64)
+		parameter = append(parameter, sig...)
+	}
+	header.Program = &program.Program{Code: code, Parameter: parameter}
+}
+
+func TestHeaderVerifierMultiSig(t *testing.T) {
+	bookKeepers := newTestAccounts(t, 3)
+	outsiders := newTestAccounts(t, 2)
+	pubKeys := make([]*crypto.PubKey, 0, len(bookKeepers))
+	for _, bookKeeper := range bookKeepers {
+		pubKeys = append(pubKeys, bookKeeper.PubKey())
+	}
+	code, err := contract.CreateMultiSigRedeemScript(2, pubKeys)
+	if err != nil {
+		t.Fatalf("CreateMultiSigRedeemScript error:%s", err)
+	}
+	bookKeeper, err := ToCodeHash(code)
+	if err != nil {
+		t.Fatalf("ToCodeHash error:%s", err)
+	}
+	ordered := contractOrder(t, code, bookKeepers)
+	singleCode, err := contract.CreateSignatureRedeemScript(ordered[0].PubKey())
+	if err != nil {
+		t.Fatalf("CreateSignatureRedeemScript error:%s", err)
+	}
+	checkpoint := &ledger.Blockdata{Height: 10, Timestamp: 1000, NextBookKeeper: bookKeeper}
+
+	cases := []struct {
+		name    string
+		code    []byte
+		signers []*account.Account
+		//modify change the header after it is signed
+		modify func(header *ledger.Blockdata)
+		//check is the failed check, empty if the header is valid
+		check string
+	}{
+		{name: "2 of 3", code: code, signers: ordered[:2]},
+		{name: "3 of 3", code: code, signers: ordered},
+		{name: "first and last", code: code, signers: []*account.Account{ordered[0], ordered[2]}},
+		{name: "1 of 3", code: code, signers: ordered[:1], check: BLOCK_CHECK_SIGNATURE},
+		{name: "no signature", code: code, check: BLOCK_CHECK_SIGNATURE},
+		{name: "outsiders", code: code, signers: outsiders, check: BLOCK_CHECK_SIGNATURE},
+		{name: "outsider and bookkeeper", code: code, signers: []*account.Account{ordered[0], outsiders[0]}, check: BLOCK_CHECK_SIGNATURE},
+		{name: "out of order", code: code, signers: []*account.Account{ordered[1], ordered[0]}, check: BLOCK_CHECK_SIGNATURE},
+		{name: "another contract", code: singleCode, signers: ordered[:1], check: BLOCK_CHECK_BOOKKEEPER},
+		{
+			name:    "changed after signed",
+			code:    code,
+			signers: ordered[:2],
+			modify:  func(header *ledger.Blockdata) { header.Timestamp++ },
+			check:   BLOCK_CHECK_SIGNATURE,
+		},
+		{
+			name:    "wrong height",
+			code:    code,
+			signers: ordered[:2],
+			modify:  func(header *ledger.Blockdata) { header.Height++ },
+			check:   BLOCK_CHECK_HEIGHT,
+		},
+		{
+			name:    "wrong previous hash",
+			code:    code,
+			signers: ordered[:2],
+			modify:  func(header *ledger.Blockdata) { header.PrevBlockHash = Uint256{1} },
+			check:   BLOCK_CHECK_PREV_HASH,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			verifier, err := NewHeaderVerifier(checkpoint)
+			if err != nil {
+				t.Fatalf("NewHeaderVerifier error:%s", err)
+			}
+			header := &ledger.Blockdata{
+				PrevBlockHash:  checkpoint.Hash(),
+				Timestamp:      checkpoint.Timestamp + 1,
+				Height:         checkpoint.Height + 1,
+				NextBookKeeper: bookKeeper,
+			}
+			signHeader(t, header, c.code, c.signers)
+			if c.modify != nil {
+				c.modify(header)
+			}
+
+			err = verifier.Verify(header)
+			if c.check == "" {
+				if err != nil {
+					t.Fatalf("Verify error:%s", err)
+				}
+				if verifier.Last() != header {
+					t.Fatalf("Verify does not keep the header as the last")
+				}
+				return
+			}
+			validationErr := &BlockValidationError{}
+			if !errors.As(err, &validationErr) || validationErr.Check != c.check {
+				t.Fatalf("Verify error:%v expected check:%s", err, c.check)
+			}
+			if verifier.Last() != checkpoint {
+				t.Fatalf("Verify keep a header failing the check:%s", c.check)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package dnasdk
 
 import (
 	"encoding/json"
-	"fmt"
 	"strings"
 )
 
@@ -16,6 +15,8 @@ const (
 	DNA_RPC_GETUNSPENDOUTPUT    = "getunspendoutput"
 	DNA_RPC_GETCURRENTBLOCKHASH = "getbestblockhash"
 	DNA_RPC_GETIDENTITYUPDATE   = "getidentityupdate"
+	//DNA_RPC_GETASSET is only served by RestTransport with DNA_API_GETASSET
+	DNA_RPC_GETASSET = "getasset"
 )
 
 const (
@@ -44,6 +45,15 @@ type DNAJsonRpcRes struct {
 	Id      string          `json:"id"`
 	JsonRpc string          `json:"jsonrpc"`
 	Result  json.RawMessage `json:"result"`
+	Error   json.RawMessage `json:"error"`
+}
+
+type DNARestRes struct {
+	Action  string          `json:"Action"`
+	Desc    string          `json:"Desc"`
+	Error   int64           `json:"Error"`
+	Result  json.RawMessage `json:"Result"`
+	Version string          `json:"Version"`
 }
 
 const (
@@ -58,6 +68,7 @@ const (
 	DnaRpcInternalError      = "internal error"
 )
 
+// Deprecated: DNARpcError is kept for compatibility, use the Err* errors with errors.Is
 var DNARpcError map[string]string = map[string]string{
 	DnaRpcInvalidHash:        "",
 	DnaRpcInvalidBlock:       "",
@@ -70,11 +81,40 @@ var DNARpcError map[string]string = map[string]string{
 	DnaRpcNil:                "",
 }
 
+// HandleResult return the result data, quote of JSON string is trimmed.
+// The error of node is returned as RpcError or one of the Err* errors.
 func (this *DNAJsonRpcRes) HandleResult() ([]byte, error) {
-	res := strings.Trim(string(this.Result), "\"")
-	_, ok := DNARpcError[res]
-	if ok {
-		return nil, fmt.Errorf(res)
+	rpcErr := this.rpcError()
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	res := string(this.Result)
+	if len(res) >= 2 && res[0] == '"' && res[len(res)-1] == '"' {
+		res = res[1 : len(res)-1]
+		err, ok := dnaRpcErrors[res]
+		if ok {
+			return nil, err
+		}
 	}
 	return []byte(res), nil
 }
+
+func (this *DNAJsonRpcRes) rpcError() *RpcError {
+	errData := strings.TrimSpace(string(this.Error))
+	if errData == "" || errData == DnaRpcNil || errData == "0" {
+		return nil
+	}
+	rpcErr := &RpcError{}
+	err := json.Unmarshal(this.Error, rpcErr)
+	if err != nil {
+		return &RpcError{Message: strings.Trim(errData, "\"")}
+	}
+	return rpcErr
+}
+
+func (this *DNARestRes) HandleResult() ([]byte, error) {
+	if this.Error != 0 {
+		return nil, &RpcError{Code: this.Error, Message: this.Desc}
+	}
+	return []byte(strings.Trim(string(this.Result), "\"")), nil
+}
@@ -0,0 +1,202 @@
+package dnasdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by LoadClientConfig, they override the config file
+const (
+	ENV_RPC_ADDRESSES = "DNASDK_RPC_ADDRESSES"
+	ENV_TRANSPORT     = "DNASDK_TRANSPORT"
+	ENV_TIMEOUT       = "DNASDK_TIMEOUT"
+	ENV_IS_TLS        = "DNASDK_IS_TLS"
+	ENV_CERT_PATH     = "DNASDK_CERT_PATH"
+	ENV_KEY_PATH      = "DNASDK_KEY_PATH"
+	ENV_CA_PATH       = "DNASDK_CA_PATH"
+	ENV_SERVER_NAME   = "DNASDK_SERVER_NAME"
+	ENV_WALLET_DIR    = "DNASDK_WALLET_DIR"
+	ENV_ENCRYPT_ALG   = "DNASDK_ENCRYPT_ALG"
+)
+
+const (
+	TRANSPORT_JSONRPC = "jsonrpc"
+	TRANSPORT_REST    = "rest"
+)
+
+// ClientConfig is the "Configuration" of a config file like examples/config.json.
+// The fields of node config are used to find the node: with no RpcAddresses, the address
+// is built from Host and HttpJsonPort, or HttpRestPort for the rest transport.
+// Timeouts are in seconds.
+type ClientConfig struct {
+	RpcAddresses        []string
+	Host                string
+	HttpJsonPort        int
+	HttpRestPort        int
+	Transport           string
+	Timeout             int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     int
+	DisableKeepAlives   bool
+	IsTLS               bool
+	CertPath            string
+	KeyPath             string
+	CAPath              string
+	ServerName          string
+	WalletDir           string
+	EncryptAlg          string
+}
+
+type clientConfigFile struct {
+	Configuration *ClientConfig
+}
+
+// LoadClientConfig read ClientConfig from the JSON file at path, then from the DNASDK_* environment
+// variables. With empty path, only environment variables are read.
+func LoadClientConfig(path string) (*ClientConfig, error) {
+	config := &ClientConfig{}
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ReadFile:%s error:%w", path, err)
+		}
+		file := &clientConfigFile{Configuration: config}
+		err = json.Unmarshal(data, file)
+		if err != nil {
+			return nil, fmt.Errorf("json.Unmarshal config:%s error:%w", path, err)
+		}
+	}
+	err := config.loadEnv()
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (this *ClientConfig) loadEnv() error {
+	if value := os.Getenv(ENV_RPC_ADDRESSES); value != "" {
+		this.RpcAddresses = strings.Split(strings.Trim(value, ";"), ";")
+	}
+	if value := os.Getenv(ENV_TRANSPORT); value != "" {
+		this.Transport = value
+	}
+	if value := os.Getenv(ENV_TIMEOUT); value != "" {
+		timeout, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s:%s error:%w", ENV_TIMEOUT, value, err)
+		}
+		this.Timeout = timeout
+	}
+	if value := os.Getenv(ENV_IS_TLS); value != "" {
+		isTLS, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s:%s error:%w", ENV_IS_TLS, value, err)
+		}
+		this.IsTLS = isTLS
+	}
+	if value := os.Getenv(ENV_CERT_PATH); value != "" {
+		this.CertPath = value
+	}
+	if value := os.Getenv(ENV_KEY_PATH); value != "" {
+		this.KeyPath = value
+	}
+	if value := os.Getenv(ENV_CA_PATH); value != "" {
+		this.CAPath = value
+	}
+	if value := os.Getenv(ENV_SERVER_NAME); value != "" {
+		this.ServerName = value
+	}
+	if value := os.Getenv(ENV_WALLET_DIR); value != "" {
+		this.WalletDir = value
+	}
+	if value := os.Getenv(ENV_ENCRYPT_ALG); value != "" {
+		this.EncryptAlg = value
+	}
+	return nil
+}
+
+// GetRpcAddresses return RpcAddresses, or the address built from Host and port of node
+func (this *ClientConfig) GetRpcAddresses() []string {
+	if len(this.RpcAddresses) > 0 {
+		return this.RpcAddresses
+	}
+	port := this.HttpJsonPort
+	if strings.ToLower(this.Transport) == TRANSPORT_REST {
+		port = this.HttpRestPort
+	}
+	if port == 0 {
+		return nil
+	}
+	host := this.Host
+	if host == "" {
+		host = "localhost"
+	}
+	scheme := "http"
+	if this.IsTLS {
+		scheme = "https"
+	}
+	return []string{fmt.Sprintf("%s://%s:%d", scheme, host, port)}
+}
+
+// Options return the ClientOption of the config
+func (this *ClientConfig) Options() ([]ClientOption, error) {
+	opts := make([]ClientOption, 0)
+	switch strings.ToLower(this.Transport) {
+	case "", TRANSPORT_JSONRPC:
+	case TRANSPORT_REST:
+		opts = append(opts, WithRestTransport())
+	default:
+		return nil, fmt.Errorf("unsupported transport:%s", this.Transport)
+	}
+	if this.Timeout > 0 {
+		opts = append(opts, WithTimeout(time.Duration(this.Timeout)*time.Second))
+	}
+	if this.MaxIdleConnsPerHost > 0 {
+		opts = append(opts, WithMaxIdleConnsPerHost(this.MaxIdleConnsPerHost))
+	}
+	if this.IdleConnTimeout > 0 {
+		opts = append(opts, WithIdleConnTimeout(time.Duration(this.IdleConnTimeout)*time.Second))
+	}
+	if this.DisableKeepAlives {
+		opts = append(opts, WithKeepAlive(false))
+	}
+	if this.IsTLS {
+		opts = append(opts, WithTLS(&TLSOptions{
+			CAPath:     this.CAPath,
+			CertPath:   this.CertPath,
+			KeyPath:    this.KeyPath,
+			ServerName: this.ServerName,
+		}))
+	}
+	if this.WalletDir != "" {
+		opts = append(opts, WithWalletDir(this.WalletDir))
+	}
+	if this.EncryptAlg != "" {
+		opts = append(opts, WithCryptoAlg(this.EncryptAlg))
+	}
+	return opts, nil
+}
+
+// NewDnaClientFromConfig create DnaClient from the config file at path and the DNASDK_* environment
+// variables. opts are applied after the config, WithHttpClient keeps the settings of the config
+// not set in its client.
+func NewDnaClientFromConfig(path string, opts ...ClientOption) (*DnaClient, error) {
+	config, err := LoadClientConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	rpcAddresses := config.GetRpcAddresses()
+	if len(rpcAddresses) == 0 {
+		return nil, fmt.Errorf("no rpc address in config:%s", path)
+	}
+	configOpts, err := config.Options()
+	if err != nil {
+		return nil, err
+	}
+	return NewDnaClientWithOptions(rpcAddresses, append(configOpts, opts...)...)
+}
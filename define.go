@@ -14,6 +14,12 @@ type BlockInfo struct {
 	Transactions []*Transactions
 }
 
+// BlockHeaderInfo is BlockInfo without transactions, they are not decoded
+type BlockHeaderInfo struct {
+	Hash      string
+	BlockData *BlockHead
+}
+
 type BlockHead struct {
 	Version          uint32
 	PrevBlockHash    string
@@ -90,6 +96,23 @@ type PayloadRecord struct {
 	RecordData string
 }
 
+type PayloadBookKeepingInfo struct {
+	Nonce uint64
+}
+
+type PayloadBookKeeperInfo struct {
+	PubKey     string
+	Action     string
+	Issuer     httpjsonrpc.IssuerInfo
+	Controller string
+}
+
+type PayloadIdentityUpdateInfo struct {
+	DID     string
+	DDO     string
+	Updater httpjsonrpc.IssuerInfo
+}
+
 type PayloadDeployCodeInfo struct {
 	Code        *httpjsonrpc.FunctionCodeInfo
 	Name        string
@@ -3,7 +3,6 @@ package dnasdk
 import (
 	"DNA/account"
 	. "DNA/common"
-	"DNA/common/log"
 	"DNA/core/asset"
 	"DNA/core/contract"
 	"DNA/core/ledger"
@@ -12,38 +11,68 @@ import (
 	"DNA/core/transaction/payload"
 	"DNA/crypto"
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	//log4 "github.com/alecthomas/log4go"
-	"io/ioutil"
-	"math/rand"
 	"net/http"
-	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 	"DNA/core/contract/program"
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
-	crypto.SetAlg("P256R1")
-	log.Init("./log/", log.Stdout)
-}
-
 type DnaClient struct {
 	qid          uint64
 	rpcAddresses []string
+	endpoints    *endpointPool
 	client       *http.Client
+	transport    Transport
+	retryPolicy  *RetryPolicy
+	walletDir    string
+	cryptoAlg    string
+	nonceSource  NonceSource
+	logger       Logger
+
+	interceptorLock   sync.RWMutex
+	interceptors      []RpcInterceptor
+	batchInterceptors []BatchInterceptor
+
+	monitorLock sync.RWMutex
+	monitor     *healthMonitor
+
+	strict bool
+
+	assetLock   sync.RWMutex
+	assets      map[Uint256]*AssetInfo
+	assetBlocks map[Uint256]*assetBlock
 }
 
+// NewDnaClient create DnaClient with the default settings, the crypto algorithm is P256R1 if not set
+// by a client before, see WithCryptoAlg.
+// The wallet and transactions use the DNA packages which write logs with DNA/common/log,
+// the application should call log.Init before use the client.
 func NewDnaClient(rpcAddresses []string) *DnaClient {
-	return &DnaClient{
+	client, _ := NewDnaClientWithOptions(rpcAddresses)
+	return client
+}
+
+// NewDnaClientWithOptions create DnaClient with the default settings of NewDnaClient changed by opts
+func NewDnaClientWithOptions(rpcAddresses []string, opts ...ClientOption) (*DnaClient, error) {
+	client := &DnaClient{
 		rpcAddresses: rpcAddresses,
+		endpoints:    newEndpointPool(rpcAddresses),
+		walletDir:    ".",
+		nonceSource:  defaultNonceSource(),
+		logger:       NopLogger{},
+		assets:       make(map[Uint256]*AssetInfo),
+		assetBlocks:  make(map[Uint256]*assetBlock),
 		client: &http.Client{
 			Transport: &http.Transport{
 				MaxIdleConnsPerHost:   50,
@@ -54,10 +83,74 @@ func NewDnaClient(rpcAddresses []string) *DnaClient {
 			Timeout: time.Second * 300,
 		},
 	}
+	client.transport = NewJsonRpcTransport(client.client)
+	for _, opt := range opts {
+		err := opt(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if client.cryptoAlg == "" {
+		setDefaultCryptoAlg()
+	} else {
+		err := setCryptoAlg(client.cryptoAlg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// SetTransport set how requests are carried to node, default is JsonRpcTransport.
+// Use NewRestTransport(client.GetHttpClient()) for the nodes only exposing the REST port.
+func (this *DnaClient) SetTransport(transport Transport) {
+	this.transport = transport
+}
+
+// SetRetryPolicy set the retry policy of requests. With nil policy, which is the default,
+// every endpoint is tried once without backoff.
+func (this *DnaClient) SetRetryPolicy(policy *RetryPolicy) {
+	this.retryPolicy = policy
+}
+
+// GetHttpClient return the http client shared by the transports of DnaClient
+func (this *DnaClient) GetHttpClient() *http.Client {
+	return this.client
+}
+
+// SetEndpointSelector set the policy used to choose among rpcAddresses. Default is round-robin.
+func (this *DnaClient) SetEndpointSelector(selector EndpointSelector) {
+	this.endpoints.setSelector(selector)
+}
+
+// SetEndpointCooldown set how long a failed endpoint is taken out of rotation
+func (this *DnaClient) SetEndpointCooldown(cooldown time.Duration) {
+	this.endpoints.setCooldown(cooldown)
+}
+
+// GetEndpoints return the endpoints of rpcAddresses with their health and height
+func (this *DnaClient) GetEndpoints() []*Endpoint {
+	return this.endpoints.all()
+}
+
+// RefreshEndpointHeights query block count of every endpoint, used by HighestHeightSelector
+func (this *DnaClient) RefreshEndpointHeights() error {
+	return this.RefreshEndpointHeightsContext(context.Background())
+}
+
+func (this *DnaClient) RefreshEndpointHeightsContext(ctx context.Context) error {
+	var lastErr error
+	for _, endpoint := range this.endpoints.all() {
+		_, err := this.callEndpoint(ctx, endpoint, DNA_RPC_GETBLOCKCOUNT, []interface{}{})
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
 }
 
 func (this *DnaClient) GetWalletClient(name string) *account.ClientImpl {
-	path := fmt.Sprintf("./wallet_%s.txt", name)
+	path := filepath.Join(this.walletDir, fmt.Sprintf("wallet_%s.txt", name))
 	if FileExisted(path) {
 		return account.Open(path, []byte("dna"))
 	}
@@ -66,9 +159,13 @@ func (this *DnaClient) GetWalletClient(name string) *account.ClientImpl {
 }
 
 func (this *DnaClient) GetVersion() (string, error) {
-	data, err := this.sendRpcRequest(DNA_RPC_GETVERSION, []interface{}{})
+	return this.GetVersionContext(context.Background())
+}
+
+func (this *DnaClient) GetVersionContext(ctx context.Context) (string, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETVERSION, []interface{}{})
 	if err != nil {
-		return "", fmt.Errorf("SendRpcRequest error:%s", err)
+		return "", fmt.Errorf("SendRpcRequest error:%w", err)
 	}
 	return string(data), nil
 }
@@ -87,81 +184,231 @@ func (this *DnaClient) CreateAsset(
 }
 
 func (this *DnaClient) GetBlockByHash(hash Uint256) (*ledger.Block, error) {
+	return this.GetBlockByHashContext(context.Background(), hash)
+}
+
+func (this *DnaClient) GetBlockByHashContext(ctx context.Context, hash Uint256) (*ledger.Block, error) {
 	blockHash := Uint256ToString(hash)
-	data, err := this.sendRpcRequest(DNA_RPC_GETBLOCK, []interface{}{Uint256ToString(hash)})
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETBLOCK, []interface{}{Uint256ToString(hash)})
 	if err != nil {
-		return nil, fmt.Errorf("sendRpcRequest error:%s", err)
+		return nil, fmt.Errorf("sendRpcRequest error:%w", err)
+	}
+	if isNullResult(data) {
+		return nil, ErrUnknownBlock
 	}
 	blockInfo := &BlockInfo{}
 	err = json.Unmarshal(data, blockInfo)
 	if err != nil {
-		return nil, fmt.Errorf("json.Unmarshal BlockInfo:%s error:%s", blockInfo, err)
+		return nil, fmt.Errorf("json.Unmarshal BlockInfo:%s error:%w", blockInfo, err)
+	}
+	block, err := this.parseBlock(blockInfo)
+	if err != nil {
+		return nil, fmt.Errorf("ParseBlock Hash:%x error:%w", blockHash, err)
 	}
-	block, err := ParseBlock(blockInfo)
+	err = this.checkBlockHash(block.Blockdata, hash)
 	if err != nil {
-		return nil, fmt.Errorf("ParseBlock Hash:%x error:%s", blockHash, err)
+		return nil, err
 	}
 	return block, nil
 }
 
 func (this *DnaClient) GetBlockByHeight(height uint32) (*ledger.Block, error) {
-	data, err := this.sendRpcRequest(DNA_RPC_GETBLOCK, []interface{}{height})
+	return this.GetBlockByHeightContext(context.Background(), height)
+}
+
+func (this *DnaClient) GetBlockByHeightContext(ctx context.Context, height uint32) (*ledger.Block, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETBLOCK, []interface{}{height})
 	if err != nil {
-		return nil, fmt.Errorf("sendRpcRequest error:%s", err)
+		return nil, fmt.Errorf("sendRpcRequest error:%w", err)
+	}
+	if isNullResult(data) {
+		return nil, ErrUnknownBlock
 	}
 	blockInfo := &BlockInfo{}
 	err = json.Unmarshal(data, blockInfo)
 	if err != nil {
-		return nil, fmt.Errorf("json.Unmarshal BlockInfo:%s error:%s", blockInfo, err)
+		return nil, fmt.Errorf("json.Unmarshal BlockInfo:%s error:%w", blockInfo, err)
+	}
+	block, err := this.parseBlock(blockInfo)
+	if err != nil {
+		return nil, fmt.Errorf("ParseBlock Hright:%v error:%w", height, err)
 	}
-	block, err := ParseBlock(blockInfo)
+	err = this.checkBlockHeight(block.Blockdata, height)
 	if err != nil {
-		return nil, fmt.Errorf("ParseBlock Hright:%v error:%s", height, err)
+		return nil, err
 	}
 	return block, nil
 }
 
+func (this *DnaClient) GetBlockHeaderByHash(hash Uint256) (*ledger.Blockdata, error) {
+	return this.GetBlockHeaderByHashContext(context.Background(), hash)
+}
+
+// GetBlockHeaderByHashContext return the header of block, without parsing the transactions
+func (this *DnaClient) GetBlockHeaderByHashContext(ctx context.Context, hash Uint256) (*ledger.Blockdata, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETBLOCK, []interface{}{Uint256ToString(hash)})
+	if err != nil {
+		return nil, fmt.Errorf("sendRpcRequest error:%w", err)
+	}
+	header, err := this.parseBlockHeader(data)
+	if err != nil {
+		return nil, fmt.Errorf("parseBlockHeader Hash:%x error:%w", hash, err)
+	}
+	err = this.checkBlockHash(header, hash)
+	if err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func (this *DnaClient) GetBlockHeaderByHeight(height uint32) (*ledger.Blockdata, error) {
+	return this.GetBlockHeaderByHeightContext(context.Background(), height)
+}
+
+// GetBlockHeaderByHeightContext return the header of block, without parsing the transactions
+func (this *DnaClient) GetBlockHeaderByHeightContext(ctx context.Context, height uint32) (*ledger.Blockdata, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETBLOCK, []interface{}{height})
+	if err != nil {
+		return nil, fmt.Errorf("sendRpcRequest error:%w", err)
+	}
+	header, err := this.parseBlockHeader(data)
+	if err != nil {
+		return nil, fmt.Errorf("parseBlockHeader Height:%v error:%w", height, err)
+	}
+	err = this.checkBlockHeight(header, height)
+	if err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func (this *DnaClient) parseBlockHeader(data []byte) (*ledger.Blockdata, error) {
+	if isNullResult(data) {
+		return nil, ErrUnknownBlock
+	}
+	headerInfo := &BlockHeaderInfo{}
+	err := json.Unmarshal(data, headerInfo)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal BlockHeaderInfo:%s error:%w", data, err)
+	}
+	header, err := ParseBlockHead(headerInfo.BlockData)
+	if err != nil {
+		return nil, err
+	}
+	if this.strict {
+		err = VerifyBlockHash(header, headerInfo.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return header, nil
+}
+
+// SetStrictMode set whether the hashes of the blocks and transactions returned by node are
+// checked against their content, and against the hash or height requested. On mismatch
+// IntegrityError, or an error wrapping ErrIntegrity for height, is returned.
+func (this *DnaClient) SetStrictMode(strict bool) {
+	this.strict = strict
+}
+
+func (this *DnaClient) parseBlock(blockInfo *BlockInfo) (*ledger.Block, error) {
+	return parseBlock(blockInfo, this.strict)
+}
+
+func (this *DnaClient) parseTransaction(txStr *Transactions) (*transaction.Transaction, error) {
+	return parseTransaction(txStr, this.strict)
+}
+
+// checkBlockHash check in strict mode the block returned by node is the block of hash requested
+func (this *DnaClient) checkBlockHash(header *ledger.Blockdata, hash Uint256) error {
+	if !this.strict {
+		return nil
+	}
+	computed := header.Hash()
+	if computed != hash {
+		return &IntegrityError{Object: "block", Hash: hash, Computed: computed}
+	}
+	return nil
+}
+
+// checkBlockHeight check in strict mode the block returned by node is the block of height requested
+func (this *DnaClient) checkBlockHeight(header *ledger.Blockdata, height uint32) error {
+	if this.strict && header.Height != height {
+		return fmt.Errorf("%w block height:%d requested height:%d", ErrIntegrity, header.Height, height)
+	}
+	return nil
+}
+
+// checkTransactionHash check in strict mode the transaction returned by node is the one of txHash requested.
+// The hash of tx is already checked against its content by parseTransaction.
+func (this *DnaClient) checkTransactionHash(tx *transaction.Transaction, txHash Uint256) error {
+	if !this.strict {
+		return nil
+	}
+	computed := tx.Hash()
+	if computed != txHash {
+		return &IntegrityError{Object: "transaction", Hash: txHash, Computed: computed}
+	}
+	return nil
+}
+
 func (this *DnaClient) GetBlockHash(height uint32) (Uint256, error) {
-	data, err := this.sendRpcRequest(DNA_RPC_GETBLOCKHASH, []interface{}{height})
+	return this.GetBlockHashContext(context.Background(), height)
+}
+
+func (this *DnaClient) GetBlockHashContext(ctx context.Context, height uint32) (Uint256, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETBLOCKHASH, []interface{}{height})
 	if err != nil {
-		return Uint256{}, fmt.Errorf("sendRpcRequest error:%s", err)
+		return Uint256{}, fmt.Errorf("sendRpcRequest error:%w", err)
 	}
 	hash, err := ParseUint256FromString(string(data))
 	if err != nil {
-		return Uint256{}, fmt.Errorf("ParseUint256FromString Hash:%s error:%s", data, err)
+		return Uint256{}, fmt.Errorf("ParseUint256FromString Hash:%s error:%w", data, err)
 	}
 	return hash, nil
 }
 
 func (this *DnaClient) GetCurrentBlockHash() (Uint256, error) {
-	data, err := this.sendRpcRequest(DNA_RPC_GETCURRENTBLOCKHASH, []interface{}{})
+	return this.GetCurrentBlockHashContext(context.Background())
+}
+
+func (this *DnaClient) GetCurrentBlockHashContext(ctx context.Context) (Uint256, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETCURRENTBLOCKHASH, []interface{}{})
 	if err != nil {
-		return Uint256{}, fmt.Errorf("sendRpcRequest error:%s", err)
+		return Uint256{}, fmt.Errorf("sendRpcRequest error:%w", err)
 	}
 	hash, err := ParseUint256FromString(string(data))
 	if err != nil {
-		return Uint256{}, fmt.Errorf("ParseUint256FromString:%s error:%s", hash, err)
+		return Uint256{}, fmt.Errorf("ParseUint256FromString:%s error:%w", hash, err)
 	}
 	return hash, nil
 }
 
 func (this *DnaClient) GetBlockCount() (uint32, error) {
-	data, err := this.sendRpcRequest(DNA_RPC_GETBLOCKCOUNT, []interface{}{})
+	return this.GetBlockCountContext(context.Background())
+}
+
+func (this *DnaClient) GetBlockCountContext(ctx context.Context) (uint32, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETBLOCKCOUNT, []interface{}{})
 	if err != nil {
-		return 0, fmt.Errorf("sendRpcRequest error:%s", err)
+		return 0, fmt.Errorf("sendRpcRequest error:%w", err)
 	}
 	count := uint32(0)
 	err = json.Unmarshal(data, &count)
 	if err != nil {
-		return 0, fmt.Errorf("json.Unmarshal Count:%s error:%s", data, err)
+		return 0, fmt.Errorf("json.Unmarshal Count:%s error:%w", data, err)
 	}
 	return count, nil
 }
 
-func (this *DnaClient) GetIdentityUpdate(method, id string)([]byte, error){
-	data, err := this.sendRpcRequest(DNA_RPC_GETIDENTITYUPDATE, []interface{}{method, id})
+func (this *DnaClient) GetIdentityUpdate(method, id string) ([]byte, error) {
+	return this.GetIdentityUpdateContext(context.Background(), method, id)
+}
+
+func (this *DnaClient) GetIdentityUpdateContext(ctx context.Context, method, id string) ([]byte, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETIDENTITYUPDATE, []interface{}{method, id})
 	if err != nil {
-		return nil, fmt.Errorf("sendRpcRequest error:%s", err)
+		return nil, fmt.Errorf("sendRpcRequest error:%w", err)
 	}
 	return data, nil
 }
@@ -172,11 +419,11 @@ func (this *DnaClient) NewAssetRegisterTransaction(asset *asset.Asset,
 	controllerAccount *account.Account) (*transaction.Transaction, error) {
 	controller, err := contract.CreateSignatureContract(controllerAccount.PubKey())
 	if err != nil {
-		return nil, fmt.Errorf("CreateSignatureContract error:%s", err)
+		return nil, fmt.Errorf("CreateSignatureContract error:%w", err)
 	}
 	tx, err := transaction.NewRegisterAssetTransaction(asset, amount, issuer.PubKey(), controller.ProgramHash)
 	if err != nil {
-		return nil, fmt.Errorf("NewRegisterAssetTransaction error:%s", err)
+		return nil, fmt.Errorf("NewRegisterAssetTransaction error:%w", err)
 	}
 	this.setNonce(tx)
 	return tx, nil
@@ -185,7 +432,7 @@ func (this *DnaClient) NewAssetRegisterTransaction(asset *asset.Asset,
 func (this *DnaClient) NewIssueAssetTransaction(txOutputs []*transaction.TxOutput) (*transaction.Transaction, error) {
 	tx, err := transaction.NewIssueAssetTransaction(txOutputs)
 	if err != nil {
-		return nil, fmt.Errorf("NewIssueAssetTransaction error:%s", err)
+		return nil, fmt.Errorf("NewIssueAssetTransaction error:%w", err)
 	}
 	this.setNonce(tx)
 	return tx, nil
@@ -195,7 +442,7 @@ func (this *DnaClient) NewTransferAssetTransaction(inputs []*transaction.UTXOTxI
 	outputs []*transaction.TxOutput) (*transaction.Transaction, error) {
 	tx, err := transaction.NewTransferAssetTransaction(inputs, outputs)
 	if err != nil {
-		return nil, fmt.Errorf("NewTransferAssetTransaction error:%s", err)
+		return nil, fmt.Errorf("NewTransferAssetTransaction error:%w", err)
 	}
 	this.setNonce(tx)
 	return tx, nil
@@ -204,7 +451,7 @@ func (this *DnaClient) NewTransferAssetTransaction(inputs []*transaction.UTXOTxI
 func (this *DnaClient) NewRecordTransaction(recordType string, recordData []byte) (*transaction.Transaction, error) {
 	tx, err := transaction.NewRecordTransaction(recordType, recordData)
 	if err != nil {
-		return nil, fmt.Errorf("NewRecordTransaction error:%s", err)
+		return nil, fmt.Errorf("NewRecordTransaction error:%w", err)
 	}
 	this.setNonce(tx)
 	return tx, nil
@@ -229,7 +476,7 @@ func (this *DnaClient) NewIdentityUpdateTransaction(pubKey *crypto.PubKey, did,
 //func (this *DnaClient) NewStateUpdateTransction(account *account.Account, namespace, key, value []byte) (*transaction.Transaction, error) {
 //	tx, err := transaction.NewStateUpdateTransaction(account.PubKey(), namespace, key, value)
 //	if err != nil {
-//		return nil, fmt.Errorf("NewStateUpdateTransaction error:%s", err)
+//		return nil, fmt.Errorf("NewStateUpdateTransaction error:%w", err)
 //	}
 //	this.setNonce(tx)
 //	return tx, nil
@@ -238,93 +485,114 @@ func (this *DnaClient) NewIdentityUpdateTransaction(pubKey *crypto.PubKey, did,
 //func (this *DnaClient) NewStateUpdaterTransaction(account *account.Account, isAdd bool, namespace []byte) (*transaction.Transaction, error) {
 //	tx, err := transaction.NewStateUpdaterTransaction(account.PubKey(), isAdd, namespace, []byte(""))
 //	if err != nil {
-//		return nil, fmt.Errorf("NewStateUpdaterTransaction error:%s", err)
+//		return nil, fmt.Errorf("NewStateUpdaterTransaction error:%w", err)
 //	}
 //	this.setNonce(tx)
 //	return tx, nil
 //}
 
 func (this *DnaClient) setNonce(tx *transaction.Transaction) {
-	attr := transaction.NewTxAttribute(transaction.Nonce, []byte(fmt.Sprintf("%d", rand.Int63())))
+	attr := transaction.NewTxAttribute(transaction.Nonce, []byte(fmt.Sprintf("%d", this.nonceSource())))
 	tx.Attributes = append(tx.Attributes, &attr)
 }
 
 func (this *DnaClient) SendTransaction(account *account.Account, tx *transaction.Transaction) (Uint256, error) {
-	err := this.SignTransaction(account, tx)
+	return this.SendTransactionContext(context.Background(), account, tx)
+}
+
+func (this *DnaClient) SendTransactionContext(ctx context.Context, account *account.Account, tx *transaction.Transaction) (Uint256, error) {
+	err := this.SignTransactionContext(ctx, account, tx)
 	if err != nil {
-		return Uint256{}, fmt.Errorf("SignTransaction error:%s", err)
+		return Uint256{}, fmt.Errorf("SignTransaction error:%w", err)
 	}
+	return this.SendRawTransactionContext(ctx, tx)
+}
 
+// SendRawTransaction send the signed tx to node. The tx is serialized once, so that
+// a retry resend the same bytes with the same tx hash.
+func (this *DnaClient) SendRawTransaction(tx *transaction.Transaction) (Uint256, error) {
+	return this.SendRawTransactionContext(context.Background(), tx)
+}
+
+func (this *DnaClient) SendRawTransactionContext(ctx context.Context, tx *transaction.Transaction) (Uint256, error) {
 	var buffer bytes.Buffer
-	err = tx.Serialize(&buffer)
+	err := tx.Serialize(&buffer)
 	if err != nil {
-		return Uint256{}, fmt.Errorf("Serialize error:%s", err)
+		return Uint256{}, fmt.Errorf("Serialize error:%w", err)
 	}
 
+	txHash := tx.Hash()
 	txData := hex.EncodeToString(buffer.Bytes())
-	data, err := this.sendRpcRequest(DNA_RPC_SENDTRANSACTION, []interface{}{txData})
+	data, attempts, err := this.sendRpcRequestAttempts(ctx, DNA_RPC_SENDTRANSACTION, []interface{}{txData})
 	if err != nil {
+		this.logger.Error("send transaction failed", TxHashField(Uint256ToString(txHash)), ErrorField(err))
+		if attempts > 1 && !errors.Is(err, ErrTransport) {
+			//an attempt before may have reached the node, the tx is not lost if node has it
+			_, getErr := this.GetTransactionContext(ctx, txHash)
+			if getErr == nil {
+				return txHash, nil
+			}
+		}
 		return Uint256{}, err
 	}
 
 	hash, err := ParseUint256FromString(string(data))
 	if err != nil {
-		return Uint256{}, fmt.Errorf("ParseUint256FromString Hash:%s error:%s", data, err)
+		return Uint256{}, fmt.Errorf("ParseUint256FromString Hash:%s error:%w", data, err)
+	}
+	if hash != txHash {
+		return Uint256{}, fmt.Errorf("tx hash:%x returned by node mismatch:%x", hash, txHash)
 	}
+	this.logger.Info("transaction sent", TxHashField(Uint256ToString(txHash)))
 	return hash, nil
 }
 
 func (this *DnaClient) SignTransaction(signer *account.Account, tx *transaction.Transaction) error {
+	return this.SignTransactionContext(context.Background(), signer, tx)
+}
+
+func (this *DnaClient) SignTransactionContext(ctx context.Context, signer *account.Account, tx *transaction.Transaction) error {
 	signature, err := signature.SignBySigner(tx, signer)
 	if err != nil {
-		return fmt.Errorf("SignBySigner error:%s", err)
+		return fmt.Errorf("SignBySigner error:%w", err)
 	}
 	transactionContract, err := contract.CreateSignatureContract(signer.PubKey())
 	if err != nil {
-		return fmt.Errorf("CreateSignatureContract error:%s", err)
+		return fmt.Errorf("CreateSignatureContract error:%w", err)
 	}
-	programHashes, err := this.GetTransactionProgramHashes(tx)
+	programHashes, err := this.GetTransactionProgramHashesContext(ctx, tx)
 	if err != nil {
-		return fmt.Errorf("GetTransactionProgramHashes error:%s", err)
+		return fmt.Errorf("GetTransactionProgramHashes error:%w", err)
 	}
-	ctx, err := this.NewContractContext(tx, programHashes)
+	contractCtx, err := this.NewContractContext(tx, programHashes)
 	if err != nil {
-		return fmt.Errorf("NewContractContext error:%s", err)
+		return fmt.Errorf("NewContractContext error:%w", err)
 	}
-	err = ctx.AddContract(transactionContract, signer.PubKey(), signature)
+	err = contractCtx.AddContract(transactionContract, signer.PubKey(), signature)
 	if err != nil {
-		return fmt.Errorf("AddContract error:%s", err)
+		return fmt.Errorf("AddContract error:%w", err)
 	}
-	tx.SetPrograms(ctx.GetPrograms())
+	tx.SetPrograms(contractCtx.GetPrograms())
 	return nil
 }
 
 func (this *DnaClient) SendMultiSigTransction(owner *account.Account, m int, singers []*account.Account, tx *transaction.Transaction) (Uint256, error) {
-	err := this.MultiSignTransaction(owner, m, singers, tx)
-	if err != nil {
-		return Uint256{}, fmt.Errorf("MultiSignTransaction error:%s", err)
-	}
-
-	var buffer bytes.Buffer
-	err = tx.Serialize(&buffer)
-	if err != nil {
-		return Uint256{}, fmt.Errorf("Serialize error:%s", err)
-	}
+	return this.SendMultiSigTransctionContext(context.Background(), owner, m, singers, tx)
+}
 
-	txData := hex.EncodeToString(buffer.Bytes())
-	data, err := this.sendRpcRequest(DNA_RPC_SENDTRANSACTION, []interface{}{txData})
+func (this *DnaClient) SendMultiSigTransctionContext(ctx context.Context, owner *account.Account, m int, singers []*account.Account, tx *transaction.Transaction) (Uint256, error) {
+	err := this.MultiSignTransactionContext(ctx, owner, m, singers, tx)
 	if err != nil {
-		return Uint256{}, err
+		return Uint256{}, fmt.Errorf("MultiSignTransaction error:%w", err)
 	}
-
-	hash, err := ParseUint256FromString(string(data))
-	if err != nil {
-		return Uint256{}, fmt.Errorf("ParseUint256FromString Hash:%s error:%s", data, err)
-	}
-	return hash, nil
+	return this.SendRawTransactionContext(ctx, tx)
 }
 
 func (this *DnaClient) MultiSignTransaction(owner *account.Account, m int, signers []*account.Account, tx *transaction.Transaction) error {
+	return this.MultiSignTransactionContext(context.Background(), owner, m, signers, tx)
+}
+
+func (this *DnaClient) MultiSignTransactionContext(ctx context.Context, owner *account.Account, m int, signers []*account.Account, tx *transaction.Transaction) error {
 	if len(signers) == 0 {
 		return fmt.Errorf("not enough signer")
 	}
@@ -333,40 +601,44 @@ func (this *DnaClient) MultiSignTransaction(owner *account.Account, m int, signe
 	for _, signer := range signers {
 		signature, err := signature.SignBySigner(tx, signer)
 		if err != nil {
-			return fmt.Errorf("SignBySigner error:%s", err)
+			return fmt.Errorf("SignBySigner error:%w", err)
 		}
 		signatures = append(signatures, signature)
 		pubKeys = append(pubKeys, signer.PubKey())
 	}
 	transactionContract, err := contract.CreateMultiSigContract(owner.ProgramHash, m, pubKeys)
 	if err != nil {
-		return fmt.Errorf("CreateMultiSigContract error:%s", err)
+		return fmt.Errorf("CreateMultiSigContract error:%w", err)
 	}
-	programHashes, err := this.GetTransactionProgramHashes(tx)
+	programHashes, err := this.GetTransactionProgramHashesContext(ctx, tx)
 	if err != nil {
-		return fmt.Errorf("GetTransactionProgramHashes error:%s", err)
+		return fmt.Errorf("GetTransactionProgramHashes error:%w", err)
 	}
-	ctx, err := this.NewContractContext(tx, programHashes)
+	contractCtx, err := this.NewContractContext(tx, programHashes)
 	if err != nil {
-		return fmt.Errorf("NewContractContext error:%s", err)
+		return fmt.Errorf("NewContractContext error:%w", err)
 	}
 	for _, signature := range signatures {
-		err = ctx.AddContract(transactionContract, owner.PubKey(), signature)
+		err = contractCtx.AddContract(transactionContract, owner.PubKey(), signature)
 		if err != nil {
-			return fmt.Errorf("AddContract error:%s", err)
+			return fmt.Errorf("AddContract error:%w", err)
 		}
 	}
-	tx.SetPrograms(ctx.GetPrograms())
+	tx.SetPrograms(contractCtx.GetPrograms())
 	return nil
 }
 
 func (this *DnaClient) GetTransactionProgramHashes(tx *transaction.Transaction) ([]Uint160, error) {
+	return this.GetTransactionProgramHashesContext(context.Background(), tx)
+}
+
+func (this *DnaClient) GetTransactionProgramHashesContext(ctx context.Context, tx *transaction.Transaction) ([]Uint160, error) {
 	hashs := []Uint160{}
 	uniqHashes := []Uint160{}
 	// add inputUTXO's transaction
-	referenceWithUTXO_Output, err := this.GetTransactionReference(tx)
+	referenceWithUTXO_Output, err := this.GetTransactionReferenceContext(ctx, tx)
 	if err != nil {
-		return nil, fmt.Errorf("Transction GetReference error:%s", err)
+		return nil, fmt.Errorf("Transction GetReference error:%w", err)
 	}
 	for _, output := range referenceWithUTXO_Output {
 		programHash := output.ProgramHash
@@ -378,51 +650,60 @@ func (this *DnaClient) GetTransactionProgramHashes(tx *transaction.Transaction)
 		}
 		dataHash, err := Uint160ParseFromBytes(attribute.Data)
 		if err != nil {
-			return nil, fmt.Errorf("Uint160ParseFromBytes error:%s", err)
+			return nil, fmt.Errorf("Uint160ParseFromBytes error:%w", err)
 		}
 		hashs = append(hashs, Uint160(dataHash))
 	}
 	switch tx.TxType {
 	case transaction.RegisterAsset:
-		issuer := tx.Payload.(*payload.RegisterAsset).Issuer
-		signatureRedeemScript, err := contract.CreateSignatureRedeemScript(issuer)
+		regAsset, ok := tx.Payload.(*payload.RegisterAsset)
+		if !ok {
+			return nil, fmt.Errorf("RegisterAsset payload type:%T error", tx.Payload)
+		}
+		signatureRedeemScript, err := contract.CreateSignatureRedeemScript(regAsset.Issuer)
 		if err != nil {
-			return nil, fmt.Errorf("CreateSignatureRedeemScript error:%s", err)
+			return nil, fmt.Errorf("CreateSignatureRedeemScript error:%w", err)
 		}
 		astHash, err := ToCodeHash(signatureRedeemScript)
 		if err != nil {
-			return nil, fmt.Errorf("ToCodeHash error:%s", err)
+			return nil, fmt.Errorf("ToCodeHash error:%w", err)
 		}
 		hashs = append(hashs, astHash)
 	case transaction.IssueAsset:
 		result := tx.GetMergedAssetIDValueFromOutputs()
 		if err != nil {
-			return nil, fmt.Errorf("GetMergedAssetIDValueFromOutputs error:%s", err)
+			return nil, fmt.Errorf("GetMergedAssetIDValueFromOutputs error:%w", err)
 		}
 		for k, _ := range result {
-			regTx, err := this.GetTransaction(k)
+			regTx, err := this.GetTransactionContext(ctx, k)
 			if err != nil {
-				return nil, fmt.Errorf("GetTransaction TxHash:%x error:%s", k, err)
+				return nil, fmt.Errorf("GetTransaction TxHash:%x error:%w", k, err)
 			}
 			if regTx.TxType != transaction.RegisterAsset {
 				return nil, errors.New("Transaction is not RegisterAsset")
 			}
 
-			regPayload := regTx.Payload.(*payload.RegisterAsset)
+			regPayload, ok := regTx.Payload.(*payload.RegisterAsset)
+			if !ok {
+				return nil, fmt.Errorf("RegisterAsset payload type:%T error", regTx.Payload)
+			}
 			hashs = append(hashs, regPayload.Controller)
 		}
 	case transaction.TransferAsset:
 	case transaction.Record:
 	case transaction.BookKeeper:
 	case transaction.IdentityUpdate:
-		updater := tx.Payload.(*payload.IdentityUpdate).Updater
-		signatureRedeemScript, err := contract.CreateSignatureRedeemScript(updater)
+		identityUpdate, ok := tx.Payload.(*payload.IdentityUpdate)
+		if !ok {
+			return nil, fmt.Errorf("IdentityUpdate payload type:%T error", tx.Payload)
+		}
+		signatureRedeemScript, err := contract.CreateSignatureRedeemScript(identityUpdate.Updater)
 		if err != nil {
-			return nil, fmt.Errorf("GetProgramHashes CreateSignatureRedeemScript error:%s.", err)
+			return nil, fmt.Errorf("GetProgramHashes CreateSignatureRedeemScript error:%w.", err)
 		}
 		astHash, err := ToCodeHash(signatureRedeemScript)
 		if err != nil {
-			return nil,fmt.Errorf("ToCodeHash error:%s.", err)
+			return nil,fmt.Errorf("ToCodeHash error:%w.", err)
 		}
 		hashs = append(hashs, astHash)
 	//case transaction.StateUpdater:
@@ -430,12 +711,12 @@ func (this *DnaClient) GetTransactionProgramHashes(tx *transaction.Transaction)
 	//	updater := tx.Payload.(*payload.StateUpdate).Updater
 	//	signatureRedeemScript, err := contract.CreateSignatureRedeemScript(updater)
 	//	if err != nil {
-	//		return nil, fmt.Errorf("CreateSignatureRedeemScript error:%s.", err)
+	//		return nil, fmt.Errorf("CreateSignatureRedeemScript error:%w.", err)
 	//	}
 	//
 	//	astHash, err := ToCodeHash(signatureRedeemScript)
 	//	if err != nil {
-	//		return nil, fmt.Errorf("ToCodeHash error:%s.", err)
+	//		return nil, fmt.Errorf("ToCodeHash error:%w.", err)
 	//	}
 	//	hashs = append(hashs, astHash)
 	default:
@@ -460,7 +741,7 @@ func (this *DnaClient) NewContractContext(data signature.SignableData, programHa
 	} else {
 		proHashes, err = data.GetProgramHashes()
 		if err != nil {
-			return nil, fmt.Errorf("GetProgramHashes error:%s", err)
+			return nil, fmt.Errorf("GetProgramHashes error:%w", err)
 		}
 	}
 	hashLen := len(proHashes)
@@ -474,41 +755,56 @@ func (this *DnaClient) NewContractContext(data signature.SignableData, programHa
 }
 
 func (this *DnaClient) GetTransaction(txHash Uint256) (*transaction.Transaction, error) {
-	data, err := this.sendRpcRequest(DNA_RPC_GETTRANSACTION, []interface{}{Uint256ToString(txHash)})
+	return this.GetTransactionContext(context.Background(), txHash)
+}
+
+func (this *DnaClient) GetTransactionContext(ctx context.Context, txHash Uint256) (*transaction.Transaction, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETTRANSACTION, []interface{}{Uint256ToString(txHash)})
 	if err != nil {
-		return nil, fmt.Errorf("sendRpcRequest error:%s", err)
+		return nil, fmt.Errorf("sendRpcRequest error:%w", err)
+	}
+	if isNullResult(data) {
+		return nil, ErrUnknownTransaction
 	}
 	txStr := &Transactions{}
 	err = json.Unmarshal(data, txStr)
 	if err != nil {
-		return nil, fmt.Errorf("json.Unmarshal Transactions:%s error:%s", data, err)
+		return nil, fmt.Errorf("json.Unmarshal Transactions:%s error:%w", data, err)
 	}
-	tx, err := ParseTransaction(txStr)
+	tx, err := this.parseTransaction(txStr)
 	if err != nil {
-		return nil, fmt.Errorf("ParseTransaction:%+v error:%s", txStr, err)
+		return nil, fmt.Errorf("ParseTransaction:%+v error:%w", txStr, err)
+	}
+	err = this.checkTransactionHash(tx, txHash)
+	if err != nil {
+		return nil, err
 	}
 	return tx, nil
 }
 
 func (this *DnaClient) GetUnspendOutput(assetHash Uint256, programHash Uint160) ([]*UnspendUTXO, error) {
-	data, err := this.sendRpcRequest(DNA_RPC_GETUNSPENDOUTPUT, []interface{}{Uint160ToString(programHash), Uint256ToString(assetHash)})
+	return this.GetUnspendOutputContext(context.Background(), assetHash, programHash)
+}
+
+func (this *DnaClient) GetUnspendOutputContext(ctx context.Context, assetHash Uint256, programHash Uint160) ([]*UnspendUTXO, error) {
+	data, err := this.sendRpcRequest(ctx, DNA_RPC_GETUNSPENDOUTPUT, []interface{}{Uint160ToString(programHash), Uint256ToString(assetHash)})
 	if err != nil {
-		return nil, fmt.Errorf("sendRpcRequest error:%s", err)
+		return nil, fmt.Errorf("sendRpcRequest error:%w", err)
 	}
-	if string(data) == "{}" {
+	if string(data) == "{}" || isNullResult(data) {
 		return nil, nil
 	}
 	outputMap := make(map[string]json.RawMessage, 0)
 	err = json.Unmarshal(data, &outputMap)
 	if err != nil {
-		return nil, fmt.Errorf("json.Unmarshal map[string]json.RawMessage:%s error:%s", data, err)
+		return nil, fmt.Errorf("json.Unmarshal map[string]json.RawMessage:%s error:%w", data, err)
 	}
 	unspents := make([]*UnspendUTXO, 0, len(outputMap))
 	for k, o := range outputMap {
 		output := &TxoutputInfo{}
 		err := json.Unmarshal(o, output)
 		if err != nil {
-			return nil, fmt.Errorf("json.Unmarshal TxoutputInfo:%s error:%s", output, err)
+			return nil, fmt.Errorf("json.Unmarshal TxoutputInfo:%s error:%w", output, err)
 		}
 		txOutput, err := ParseTransactionOutputs(output)
 		ks := strings.Split(k, ":")
@@ -517,11 +813,11 @@ func (this *DnaClient) GetUnspendOutput(assetHash Uint256, programHash Uint160)
 		}
 		referId, err := ParseUint256FromString(ks[0])
 		if err != nil {
-			return nil, fmt.Errorf("ParseUint256FromString:%x error:%s", ks[0], err)
+			return nil, fmt.Errorf("ParseUint256FromString:%x error:%w", ks[0], err)
 		}
 		index, err := strconv.ParseInt(ks[1], 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("strconv.ParseInt:%s error:%s", ks[1], err)
+			return nil, fmt.Errorf("strconv.ParseInt:%s error:%w", ks[1], err)
 		}
 		unspent := &UnspendUTXO{
 			AssetID:            txOutput.AssetID,
@@ -531,7 +827,7 @@ func (this *DnaClient) GetUnspendOutput(assetHash Uint256, programHash Uint160)
 			ReferTxOutputIndex: uint16(index),
 		}
 		if err != nil {
-			return nil, fmt.Errorf("ParseTransactionOutputs:%s error:%s", txOutput, err)
+			return nil, fmt.Errorf("ParseTransactionOutputs:%s error:%w", txOutput, err)
 		}
 		unspents = append(unspents, unspent)
 	}
@@ -539,31 +835,44 @@ func (this *DnaClient) GetUnspendOutput(assetHash Uint256, programHash Uint160)
 }
 
 func (this *DnaClient) WaitForGenerateBlock(timeout time.Duration, blockCount ...uint32) (bool, error) {
+	if timeout < time.Second {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	ok, err := this.WaitForGenerateBlockContext(ctx, blockCount...)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false, nil
+	}
+	return ok, err
+}
+
+// WaitForGenerateBlockContext wait until blockCount blocks generated, or ctx is done
+func (this *DnaClient) WaitForGenerateBlockContext(ctx context.Context, blockCount ...uint32) (bool, error) {
 	count := uint32(2)
 	if len(blockCount) > 0 && blockCount[0] > 0 {
 		count = blockCount[0]
 	}
-	blockHeight, err := this.GetBlockCount()
+	blockHeight, err := this.GetBlockCountContext(ctx)
 	if err != nil {
-		return false, fmt.Errorf("GetBlockCount error:%s", err)
-	}
-	secs := int(timeout / time.Second)
-	if secs <= 0 {
-		secs = 1
-	}
-	ok := false
-	for i := 0; i < secs; i++ {
-		time.Sleep(time.Second)
-		curBlockHeigh, err := this.GetBlockCount()
+		return false, fmt.Errorf("GetBlockCount error:%w", err)
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-ticker.C:
+		}
+		curBlockHeigh, err := this.GetBlockCountContext(ctx)
 		if err != nil {
 			continue
 		}
 		if curBlockHeigh-blockHeight >= count {
-			ok = true
-			break
+			return true, nil
 		}
 	}
-	return ok, nil
 }
 
 func (this *DnaClient) MakeAssetAmount(rawAmont float64) Fixed64 {
@@ -577,7 +886,7 @@ func (this *DnaClient) GetRawAssetAmount(assetAmount Fixed64) float64 {
 func (this *DnaClient) GetAccountProgramHash(account *account.Account) (Uint160, error) {
 	ctr, err := contract.CreateSignatureContract(account.PubKey())
 	if err != nil {
-		return Uint160{}, fmt.Errorf("CreateSignatureContract error:%s", err)
+		return Uint160{}, fmt.Errorf("CreateSignatureContract error:%w", err)
 	}
 	return ctr.ProgramHash, nil
 }
@@ -592,7 +901,7 @@ func (this *DnaClient) GetAccountsProgramHash(owner *account.Account, m int, acc
 	}
 	ctr, err := contract.CreateMultiSigContract(owner.ProgramHash, m, pubKeys)
 	if err != nil {
-		return Uint160{}, fmt.Errorf("CreateMultiSigContract error:%s", err)
+		return Uint160{}, fmt.Errorf("CreateMultiSigContract error:%w", err)
 	}
 	return ctr.ProgramHash, nil
 }
@@ -601,14 +910,11 @@ func (this *DnaClient) getQid() string {
 	return fmt.Sprintf("%d", atomic.AddUint64(&this.qid, 1))
 }
 
-func (this *DnaClient) getRpcAddress() string {
-	if len(this.rpcAddresses) == 0 {
-		return ""
-	}
-	return this.rpcAddresses[0]
+func (this *DnaClient) GetTransactionReference(tx *transaction.Transaction) (map[*transaction.UTXOTxInput]*transaction.TxOutput, error) {
+	return this.GetTransactionReferenceContext(context.Background(), tx)
 }
 
-func (this *DnaClient) GetTransactionReference(tx *transaction.Transaction) (map[*transaction.UTXOTxInput]*transaction.TxOutput, error) {
+func (this *DnaClient) GetTransactionReferenceContext(ctx context.Context, tx *transaction.Transaction) (map[*transaction.UTXOTxInput]*transaction.TxOutput, error) {
 	if tx.TxType == transaction.RegisterAsset {
 		return nil, nil
 	}
@@ -616,9 +922,9 @@ func (this *DnaClient) GetTransactionReference(tx *transaction.Transaction) (map
 	reference := make(map[*transaction.UTXOTxInput]*transaction.TxOutput)
 	// Key index，v UTXOInput
 	for _, utxo := range tx.UTXOInputs {
-		referTx, err := this.GetTransaction(utxo.ReferTxID)
+		referTx, err := this.GetTransactionContext(ctx, utxo.ReferTxID)
 		if err != nil {
-			return nil, fmt.Errorf("GetTransaction refer txHash:%x", utxo.ReferTxID)
+			return nil, fmt.Errorf("GetTransaction refer txHash:%x error:%w", utxo.ReferTxID, err)
 		}
 		index := utxo.ReferTxOutputIndex
 		reference[utxo] = referTx.Outputs[index]
@@ -626,56 +932,144 @@ func (this *DnaClient) GetTransactionReference(tx *transaction.Transaction) (map
 	return reference, nil
 }
 
-func (this *DnaClient) sendRpcRequest(method string, params []interface{}) ([]byte, error) {
-	data, err := this.Call(this.getRpcAddress(), method, this.getQid(), params)
-	//if method == DNA_RPC_SENDTRANSACTION {
-	//	log4.Debug("Call:%s params:%+v", method, params)
-	//	log4.Debug("Res:%s", data)
-	//}
+func (this *DnaClient) sendRpcRequest(ctx context.Context, method string, params []interface{}) ([]byte, error) {
+	data, _, err := this.sendRpcRequestAttempts(ctx, method, params)
+	return data, err
+}
+
+// sendRpcRequestAttempts is sendRpcRequest which also return the count of attempts.
+// Every attempt sends the same params.
+func (this *DnaClient) sendRpcRequestAttempts(ctx context.Context, method string, params []interface{}) ([]byte, int, error) {
+	req := &RpcRequest{Method: method, Params: params, Qid: this.getQid()}
+	var res *RpcResponse
+	endpoint, attempts, err := this.roundTrip(ctx, method, func(endpoint *Endpoint) error {
+		var err error
+		res, err = this.invoke(ctx, endpoint.Address, req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("Call %s error:%s", method, err)
+		return nil, attempts, err
 	}
-	if err != nil {
-		return nil, fmt.Errorf("Call %s error:%s", method, err)
+	data, err := this.handleRpcResponse(endpoint, method, res)
+	return data, attempts, err
+}
+
+// roundTrip run call on the endpoint chosen by the selector. If the endpoint
+// cannot be reached, it is taken out of rotation and the next endpoint is tried
+// as the retry policy allows. It return the endpoint reached and the count of attempts.
+func (this *DnaClient) roundTrip(ctx context.Context, method string, call func(endpoint *Endpoint) error) (*Endpoint, int, error) {
+	policy := this.retryPolicy
+	var lastErr error
+	read := method != DNA_RPC_SENDTRANSACTION
+	if read {
+		this.refreshStaleHeights(ctx)
+	}
+	tried := make(map[*Endpoint]bool)
+	attempts := 0
+	for {
+		if policy != nil && attempts >= policy.maxAttempts(method) {
+			break
+		}
+		endpoint := this.endpoints.next(tried, read)
+		if endpoint == nil {
+			if policy == nil || len(tried) == 0 {
+				break
+			}
+			//all endpoints are tried, start another round
+			tried = make(map[*Endpoint]bool)
+			endpoint = this.endpoints.next(tried, read)
+		}
+		if policy != nil && attempts > 0 {
+			err := policy.wait(ctx, attempts)
+			if err != nil {
+				return nil, attempts, fmt.Errorf("Call %s error:%w", method, err)
+			}
+		}
+		attempts++
+		tried[endpoint] = true
+		err := call(endpoint)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, attempts, fmt.Errorf("Call %s error:%w", method, ctx.Err())
+			}
+			this.endpoints.markFailed(endpoint)
+			lastErr = &TransportError{Address: endpoint.Address, Method: method, Err: err}
+			this.logger.Warn("rpc endpoint unreachable",
+				MethodField(method),
+				EndpointField(endpoint.Address),
+				Field{Key: LOG_FIELD_ATTEMPT, Value: attempts},
+				ErrorField(err))
+			continue
+		}
+		this.endpoints.markSuccess(endpoint)
+		return endpoint, attempts, nil
 	}
-	if data == nil {
-		return nil, fmt.Errorf("Call %s return nil.", method)
+	if lastErr == nil {
+		return nil, attempts, &TransportError{Method: method, Err: fmt.Errorf("no rpc address")}
 	}
-	res := &DNAJsonRpcRes{}
-	err = json.Unmarshal(data, res)
+	return nil, attempts, lastErr
+}
+
+// refreshStaleHeights query the block count of the endpoints with a stale height, when
+// the endpoint selector use heights
+func (this *DnaClient) refreshStaleHeights(ctx context.Context) {
+	stale := this.endpoints.startRefresh()
+	if len(stale) == 0 {
+		return
+	}
+	defer this.endpoints.finishRefresh()
+	wg := &sync.WaitGroup{}
+	for _, endpoint := range stale {
+		wg.Add(1)
+		go func(endpoint *Endpoint) {
+			defer wg.Done()
+			this.callEndpoint(ctx, endpoint, DNA_RPC_GETBLOCKCOUNT, []interface{}{})
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+// callEndpoint send request to the given endpoint only, without failover
+func (this *DnaClient) callEndpoint(ctx context.Context, endpoint *Endpoint, method string, params []interface{}) ([]byte, error) {
+	res, err := this.invoke(ctx, endpoint.Address, &RpcRequest{Method: method, Params: params, Qid: this.getQid()})
 	if err != nil {
-		return nil, fmt.Errorf("json.Unmarshal DNAJsonRpcRes:%s error:%s", res, err)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("Call %s error:%w", method, ctx.Err())
+		}
+		this.endpoints.markFailed(endpoint)
+		return nil, &TransportError{Address: endpoint.Address, Method: method, Err: err}
 	}
-	data, err = res.HandleResult()
-	if err != nil {
-		return nil, err
+	this.endpoints.markSuccess(endpoint)
+	return this.handleRpcResponse(endpoint, method, res)
+}
+
+func (this *DnaClient) handleRpcResponse(endpoint *Endpoint, method string, res *RpcResponse) ([]byte, error) {
+	if res == nil {
+		return nil, fmt.Errorf("%w method:%s address:%s", ErrNoResponse, method, endpoint.Address)
 	}
-	return data, nil
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if method == DNA_RPC_GETBLOCKCOUNT {
+		count := uint32(0)
+		if json.Unmarshal(res.Result, &count) == nil {
+			endpoint.SetHeight(count)
+		}
+	}
+	return res.Result, nil
 }
 
 // Call sends RPC request to server
 func (this *DnaClient) Call(address string, method string, id interface{}, params []interface{}) ([]byte, error) {
-	data, err := json.Marshal(map[string]interface{}{
-		"method": method,
-		"id":     id,
-		"params": params,
-	})
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Marshal JSON request: %v\n", err)
-		return nil, err
-	}
-	resp, err := this.client.Post(address, "application/json", strings.NewReader(string(data)))
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "POST request: %v\n", err)
-		return nil, err
-	}
-	defer resp.Body.Close()
+	return this.CallContext(context.Background(), address, method, id, params)
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+// CallContext sends RPC request to server through the interceptors and return the raw response,
+// the request is cancelled when ctx is done
+func (this *DnaClient) CallContext(ctx context.Context, address string, method string, id interface{}, params []interface{}) ([]byte, error) {
+	res, err := this.invoke(ctx, address, &RpcRequest{Method: method, Params: params, Qid: fmt.Sprintf("%v", id)})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "GET response: %v\n", err)
 		return nil, err
 	}
-
-	return body, nil
+	return res.Raw, nil
 }
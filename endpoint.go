@@ -0,0 +1,256 @@
+package dnasdk
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEndpointCooldown is how long a failed endpoint stays out of rotation
+const DefaultEndpointCooldown = time.Second * 30
+
+// Endpoint is one DNA node rpc address known by DnaClient
+type Endpoint struct {
+	Address      string
+	height       uint32
+	heightUpdate int64
+	failures     uint32
+	downUntil    int64
+	lagging      int32
+}
+
+// Height return the last block count seen on the endpoint
+func (this *Endpoint) Height() uint32 {
+	return atomic.LoadUint32(&this.height)
+}
+
+// SetHeight update the block count of the endpoint
+func (this *Endpoint) SetHeight(height uint32) {
+	atomic.StoreUint32(&this.height, height)
+	atomic.StoreInt64(&this.heightUpdate, time.Now().UnixNano())
+}
+
+// heightStale return true when the height is not updated in maxAge
+func (this *Endpoint) heightStale(maxAge time.Duration) bool {
+	return time.Now().UnixNano()-atomic.LoadInt64(&this.heightUpdate) > int64(maxAge)
+}
+
+// Failures return the count of consecutive failed requests
+func (this *Endpoint) Failures() uint32 {
+	return atomic.LoadUint32(&this.failures)
+}
+
+// Healthy return false while the endpoint is out of rotation
+func (this *Endpoint) Healthy() bool {
+	return time.Now().UnixNano() >= atomic.LoadInt64(&this.downUntil)
+}
+
+// Lagging return true when the health monitor find the endpoint too far behind the best height.
+// Lagging endpoints are not used for reads.
+func (this *Endpoint) Lagging() bool {
+	return atomic.LoadInt32(&this.lagging) == 1
+}
+
+func (this *Endpoint) setLagging(lagging bool) {
+	if lagging {
+		atomic.StoreInt32(&this.lagging, 1)
+	} else {
+		atomic.StoreInt32(&this.lagging, 0)
+	}
+}
+
+func (this *Endpoint) markFailed(cooldown time.Duration) {
+	atomic.AddUint32(&this.failures, 1)
+	atomic.StoreInt64(&this.downUntil, time.Now().Add(cooldown).UnixNano())
+}
+
+func (this *Endpoint) markSuccess() {
+	atomic.StoreUint32(&this.failures, 0)
+	atomic.StoreInt64(&this.downUntil, 0)
+}
+
+// EndpointSelector choose the endpoint for the next request from the candidates.
+// Candidates is never empty.
+type EndpointSelector interface {
+	Select(candidates []*Endpoint) *Endpoint
+}
+
+// RoundRobinSelector use the candidates in turn
+type RoundRobinSelector struct {
+	next uint64
+}
+
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{}
+}
+
+func (this *RoundRobinSelector) Select(candidates []*Endpoint) *Endpoint {
+	n := atomic.AddUint64(&this.next, 1) - 1
+	return candidates[n%uint64(len(candidates))]
+}
+
+// RandomSelector pick a candidate at random
+type RandomSelector struct{}
+
+func NewRandomSelector() *RandomSelector {
+	return &RandomSelector{}
+}
+
+func (this *RandomSelector) Select(candidates []*Endpoint) *Endpoint {
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// DefaultHeightMaxAge is how long the heights used by HighestHeightSelector are kept
+const DefaultHeightMaxAge = time.Second * 10
+
+// HighestHeightSelector pick the candidate with the highest block count.
+// Heights are updated by GetBlockCount, DnaClient.RefreshEndpointHeights and the health monitor.
+// Before a read, DnaClient query the block count of the endpoints whose height is older than MaxHeightAge,
+// DefaultHeightMaxAge if it is zero.
+type HighestHeightSelector struct {
+	MaxHeightAge time.Duration
+}
+
+func NewHighestHeightSelector() *HighestHeightSelector {
+	return &HighestHeightSelector{MaxHeightAge: DefaultHeightMaxAge}
+}
+
+func (this *HighestHeightSelector) HeightMaxAge() time.Duration {
+	if this.MaxHeightAge <= 0 {
+		return DefaultHeightMaxAge
+	}
+	return this.MaxHeightAge
+}
+
+// HeightSelector is an EndpointSelector needing fresh endpoint heights. Before a read, DnaClient
+// query the block count of the endpoints whose height is older than HeightMaxAge.
+type HeightSelector interface {
+	EndpointSelector
+	HeightMaxAge() time.Duration
+}
+
+func (this *HighestHeightSelector) Select(candidates []*Endpoint) *Endpoint {
+	best := candidates[0]
+	for _, endpoint := range candidates[1:] {
+		if endpoint.Height() > best.Height() {
+			best = endpoint
+		}
+	}
+	return best
+}
+
+type endpointPool struct {
+	lock       sync.RWMutex
+	endpoints  []*Endpoint
+	selector   EndpointSelector
+	cooldown   time.Duration
+	refreshing int32
+}
+
+func newEndpointPool(rpcAddresses []string) *endpointPool {
+	endpoints := make([]*Endpoint, 0, len(rpcAddresses))
+	for _, address := range rpcAddresses {
+		if address == "" {
+			continue
+		}
+		endpoints = append(endpoints, &Endpoint{Address: address})
+	}
+	return &endpointPool{
+		endpoints: endpoints,
+		selector:  NewRoundRobinSelector(),
+		cooldown:  DefaultEndpointCooldown,
+	}
+}
+
+func (this *endpointPool) setSelector(selector EndpointSelector) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.selector = selector
+}
+
+func (this *endpointPool) setCooldown(cooldown time.Duration) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.cooldown = cooldown
+}
+
+func (this *endpointPool) all() []*Endpoint {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return append([]*Endpoint{}, this.endpoints...)
+}
+
+// startRefresh return the endpoints with a stale height when the selector needs heights,
+// and no other refresh is running. finishRefresh must be called after.
+func (this *endpointPool) startRefresh() []*Endpoint {
+	this.lock.RLock()
+	selector, ok := this.selector.(HeightSelector)
+	this.lock.RUnlock()
+	if !ok {
+		return nil
+	}
+	if !atomic.CompareAndSwapInt32(&this.refreshing, 0, 1) {
+		return nil
+	}
+	maxAge := selector.HeightMaxAge()
+	stale := make([]*Endpoint, 0)
+	for _, endpoint := range this.all() {
+		if endpoint.Healthy() && endpoint.heightStale(maxAge) {
+			stale = append(stale, endpoint)
+		}
+	}
+	if len(stale) == 0 {
+		this.finishRefresh()
+	}
+	return stale
+}
+
+func (this *endpointPool) finishRefresh() {
+	atomic.StoreInt32(&this.refreshing, 0)
+}
+
+// next return the endpoint for the next try, skipping the tried ones. Reads skip
+// the lagging endpoints. Unhealthy endpoints are only used when no healthy one is
+// left, so that a request is never refused only because every node failed recently.
+func (this *endpointPool) next(tried map[*Endpoint]bool, read bool) *Endpoint {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	healthy := make([]*Endpoint, 0, len(this.endpoints))
+	lagging := make([]*Endpoint, 0)
+	unhealthy := make([]*Endpoint, 0)
+	for _, endpoint := range this.endpoints {
+		if tried[endpoint] {
+			continue
+		}
+		switch {
+		case !endpoint.Healthy():
+			unhealthy = append(unhealthy, endpoint)
+		case read && endpoint.Lagging():
+			lagging = append(lagging, endpoint)
+		default:
+			healthy = append(healthy, endpoint)
+		}
+	}
+	if len(healthy) > 0 {
+		return this.selector.Select(healthy)
+	}
+	if len(lagging) > 0 {
+		return this.selector.Select(lagging)
+	}
+	if len(unhealthy) > 0 {
+		return this.selector.Select(unhealthy)
+	}
+	return nil
+}
+
+func (this *endpointPool) markFailed(endpoint *Endpoint) {
+	this.lock.RLock()
+	cooldown := this.cooldown
+	this.lock.RUnlock()
+	endpoint.markFailed(cooldown)
+}
+
+func (this *endpointPool) markSuccess(endpoint *Endpoint) {
+	endpoint.markSuccess()
+}
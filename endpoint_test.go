@@ -0,0 +1,210 @@
+package dnasdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testNode is a node of testNodes, answering getblockcount with height
+type testNode struct {
+	height uint32
+	down   bool
+	calls  map[string]int
+}
+
+// testNodes is a Transport serving the nodes by address
+type testNodes struct {
+	lock  sync.Mutex
+	nodes map[string]*testNode
+}
+
+func newTestNodes(addresses ...string) *testNodes {
+	nodes := &testNodes{nodes: make(map[string]*testNode)}
+	for _, address := range addresses {
+		nodes.nodes[address] = &testNode{calls: make(map[string]int)}
+	}
+	return nodes
+}
+
+func (this *testNodes) RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	node := this.nodes[address]
+	node.calls[req.Method]++
+	if node.down {
+		return nil, errors.New("connection refused")
+	}
+	if req.Method == DNA_RPC_GETBLOCKCOUNT {
+		return resultResponse(fmt.Sprintf("%d", node.height)), nil
+	}
+	return resultResponse(address), nil
+}
+
+func (this *testNodes) set(address string, height uint32, down bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.nodes[address].height = height
+	this.nodes[address].down = down
+}
+
+func (this *testNodes) calls(address, method string) int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.nodes[address].calls[method]
+}
+
+// newTestNodesClient return a client of nodes at addresses, GetVersion return the address reached
+func newTestNodesClient(t *testing.T, nodes *testNodes, addresses []string, opts ...ClientOption) *DnaClient {
+	opts = append([]ClientOption{
+		WithTransport(nodes),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}),
+	}, opts...)
+	client, err := NewDnaClientWithOptions(addresses, opts...)
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	return client
+}
+
+func TestEndpointFailover(t *testing.T) {
+	addresses := []string{"http://a", "http://b"}
+	nodes := newTestNodes(addresses...)
+	nodes.set("http://a", 0, true)
+	client := newTestNodesClient(t, nodes, addresses, WithEndpointCooldown(time.Millisecond*50))
+	endpoints := client.endpoints.all()
+
+	for i := 0; i < 3; i++ {
+		version, err := client.GetVersion()
+		if err != nil {
+			t.Fatalf("GetVersion error:%s", err)
+		}
+		if version != "http://b" {
+			t.Fatalf("GetVersion reached:%s expected:http://b", version)
+		}
+	}
+	//a is out of rotation after its failure
+	if nodes.calls("http://a", DNA_RPC_GETVERSION) != 1 {
+		t.Fatalf("down endpoint calls:%d expected:1", nodes.calls("http://a", DNA_RPC_GETVERSION))
+	}
+	if endpoints[0].Healthy() || endpoints[0].Failures() != 1 || !endpoints[1].Healthy() {
+		t.Fatalf("endpoint healthy:%v failures:%d, expected unhealthy after a failure",
+			endpoints[0].Healthy(), endpoints[0].Failures())
+	}
+
+	//a is back in rotation after the cooldown
+	nodes.set("http://a", 0, false)
+	time.Sleep(time.Millisecond * 60)
+	if !endpoints[0].Healthy() {
+		t.Fatalf("endpoint unhealthy after the cooldown")
+	}
+	reached := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		version, err := client.GetVersion()
+		if err != nil {
+			t.Fatalf("GetVersion error:%s", err)
+		}
+		reached[version] = true
+	}
+	if !reached["http://a"] || !reached["http://b"] {
+		t.Fatalf("GetVersion reached:%v expected both endpoints", reached)
+	}
+	if endpoints[0].Failures() != 0 {
+		t.Fatalf("endpoint failures:%d after a success expected:0", endpoints[0].Failures())
+	}
+}
+
+func TestEndpointAllDown(t *testing.T) {
+	addresses := []string{"http://a", "http://b"}
+	nodes := newTestNodes(addresses...)
+	nodes.set("http://a", 0, true)
+	nodes.set("http://b", 0, true)
+	client := newTestNodesClient(t, nodes, addresses)
+
+	_, err := client.GetVersion()
+	if !errors.Is(err, ErrTransport) {
+		t.Fatalf("GetVersion error:%v expected ErrTransport", err)
+	}
+	//the unhealthy endpoints are still tried when no healthy one is left
+	nodes.set("http://b", 0, false)
+	version, err := client.GetVersion()
+	if err != nil {
+		t.Fatalf("GetVersion error:%s", err)
+	}
+	if version != "http://b" {
+		t.Fatalf("GetVersion reached:%s expected:http://b", version)
+	}
+}
+
+func TestEndpointNextLagging(t *testing.T) {
+	pool := newEndpointPool([]string{"http://a", "http://b"})
+	endpoints := pool.all()
+	endpoints[0].setLagging(true)
+
+	for i := 0; i < 4; i++ {
+		endpoint := pool.next(map[*Endpoint]bool{}, true)
+		if endpoint != endpoints[1] {
+			t.Fatalf("read endpoint:%s expected the not lagging one", endpoint.Address)
+		}
+	}
+	sent := make(map[*Endpoint]bool)
+	for i := 0; i < 2; i++ {
+		sent[pool.next(map[*Endpoint]bool{}, false)] = true
+	}
+	if len(sent) != 2 {
+		t.Fatalf("write endpoints:%d expected the lagging one too", len(sent))
+	}
+	//the lagging endpoint is used for reads when no other is left
+	endpoint := pool.next(map[*Endpoint]bool{endpoints[1]: true}, true)
+	if endpoint != endpoints[0] {
+		t.Fatalf("read endpoint:%v expected the lagging one", endpoint)
+	}
+	endpoint = pool.next(map[*Endpoint]bool{endpoints[0]: true, endpoints[1]: true}, true)
+	if endpoint != nil {
+		t.Fatalf("read endpoint:%s expected nil when all are tried", endpoint.Address)
+	}
+}
+
+func TestHighestHeightSelector(t *testing.T) {
+	addresses := []string{"http://a", "http://b", "http://c"}
+	nodes := newTestNodes(addresses...)
+	nodes.set("http://a", 10, false)
+	nodes.set("http://b", 12, false)
+	nodes.set("http://c", 11, false)
+	client := newTestNodesClient(t, nodes, addresses, WithEndpointSelector(&HighestHeightSelector{}))
+
+	for i := 0; i < 3; i++ {
+		version, err := client.GetVersion()
+		if err != nil {
+			t.Fatalf("GetVersion error:%s", err)
+		}
+		if version != "http://b" {
+			t.Fatalf("GetVersion reached:%s expected the highest http://b", version)
+		}
+	}
+	//the heights are queried before the first read only, a zero MaxHeightAge is DefaultHeightMaxAge
+	for _, address := range addresses {
+		if nodes.calls(address, DNA_RPC_GETBLOCKCOUNT) != 1 {
+			t.Fatalf("getblockcount calls of %s:%d expected:1", address, nodes.calls(address, DNA_RPC_GETBLOCKCOUNT))
+		}
+	}
+	if (&HighestHeightSelector{}).HeightMaxAge() != DefaultHeightMaxAge {
+		t.Fatalf("HeightMaxAge of zero MaxHeightAge:%s expected:%s",
+			(&HighestHeightSelector{}).HeightMaxAge(), DefaultHeightMaxAge)
+	}
+
+	//the stale heights are queried again
+	client.endpoints.setSelector(&HighestHeightSelector{MaxHeightAge: time.Nanosecond})
+	nodes.set("http://c", 13, false)
+	time.Sleep(time.Millisecond)
+	version, err := client.GetVersion()
+	if err != nil {
+		t.Fatalf("GetVersion error:%s", err)
+	}
+	if version != "http://c" {
+		t.Fatalf("GetVersion reached:%s expected the new highest http://c", version)
+	}
+}
@@ -0,0 +1,132 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Errors returned by DNA node. Use errors.Is to check the cause of the error returned by DnaClient.
+var (
+	ErrInvalidHash        = errors.New(DnaRpcInvalidHash)
+	ErrInvalidBlock       = errors.New(DnaRpcInvalidBlock)
+	ErrInvalidTransaction = errors.New(DnaRpcInvalidTransaction)
+	ErrInvalidParameter   = errors.New(DnaRpcInvalidParameter)
+	ErrUnknownBlock       = errors.New(DnaRpcUnknownBlock)
+	ErrUnknownTransaction = errors.New(DnaRpcUnknownTransaction)
+	ErrUnsupported        = errors.New(DnaRpcUnsupported)
+	ErrInternalError      = errors.New(DnaRpcInternalError)
+)
+
+// ErrUnknownTxType is returned when parsing a transaction of a type unknown by the SDK
+var ErrUnknownTxType = errors.New("unknown transaction type")
+
+// ErrTransport is the cause of TransportError, the node cannot be reached
+var ErrTransport = errors.New("transport failure")
+
+var dnaRpcErrors = map[string]error{
+	DnaRpcInvalidHash:        ErrInvalidHash,
+	DnaRpcInvalidBlock:       ErrInvalidBlock,
+	DnaRpcInvalidTransaction: ErrInvalidTransaction,
+	DnaRpcInvalidParameter:   ErrInvalidParameter,
+	DnaRpcUnknownBlock:       ErrUnknownBlock,
+	DnaRpcUnknownTransaction: ErrUnknownTransaction,
+	DnaRpcUnsupported:        ErrUnsupported,
+	DnaRpcInternalError:      ErrInternalError,
+}
+
+// RpcError is the error object returned by node, in the JSON-RPC error field or in the REST Error code
+type RpcError struct {
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+func (this *RpcError) Error() string {
+	return fmt.Sprintf("rpc error code:%d message:%s", this.Code, this.Message)
+}
+
+// Is report whether the message of node is the one of target, like ErrUnknownBlock
+func (this *RpcError) Is(target error) bool {
+	msg := strings.ToLower(strings.Replace(this.Message, "_", " ", -1))
+	msg = strings.Replace(msg, "params", "parameter", -1)
+	err, ok := dnaRpcErrors[msg]
+	return ok && err == target
+}
+
+// TransportError is returned when the node at Address cannot be reached
+type TransportError struct {
+	Address string
+	Method  string
+	Err     error
+}
+
+func (this *TransportError) Error() string {
+	return fmt.Sprintf("Call %s address:%s error:%s", this.Method, this.Address, this.Err)
+}
+
+func (this *TransportError) Unwrap() error {
+	return this.Err
+}
+
+func (this *TransportError) Is(target error) bool {
+	return target == ErrTransport
+}
+
+// HTTPStatusError is returned when node answer with a non 200 http status. The status of a proxy
+// failing to reach node, or of an overloaded node, is a transport failure and the request is sent
+// to another endpoint. The other statuses are the answer of node to the request, like an error
+// result, and the status of an unknown route or method is ErrUnsupported.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (this *HTTPStatusError) Error() string {
+	return fmt.Sprintf("http status:%s body:%s", this.Status, this.Body)
+}
+
+func (this *HTTPStatusError) Is(target error) bool {
+	if target != ErrUnsupported {
+		return false
+	}
+	switch this.StatusCode {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// IsTransportFailure report whether the status means node cannot be reached, not an answer of node
+func (this *HTTPStatusError) IsTransportFailure() bool {
+	switch this.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isNullResult(data []byte) bool {
+	return len(data) == 0 || string(data) == DnaRpcNil
+}
+
+// ErrIntegrity is the cause of IntegrityError
+var ErrIntegrity = errors.New("integrity check failed")
+
+// IntegrityError is returned in strict mode when the Hash returned by node or requested for the
+// Object, "block" or "transaction", is not the hash Computed from its content
+type IntegrityError struct {
+	Object   string
+	Hash     Uint256
+	Computed Uint256
+}
+
+func (this *IntegrityError) Error() string {
+	return fmt.Sprintf("%s hash:%x mismatch computed hash:%x", this.Object, this.Hash.ToArray(), this.Computed.ToArray())
+}
+
+func (this *IntegrityError) Is(target error) bool {
+	return target == ErrIntegrity
+}
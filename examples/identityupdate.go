@@ -3,6 +3,7 @@ package main
 import (
 	"DNA/account"
 	. "DNASDK"
+	"context"
 	"fmt"
 	"time"
 )
@@ -16,9 +17,11 @@ func SetIdentityUpdate(client *DnaClient, account *account.Account, did, ddo []b
 	if err != nil {
 		return fmt.Errorf("SendTransaction error:%s", err)
 	}
-	_, err = client.WaitForGenerateBlock(30*time.Second, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, _, err = client.WaitForSentTransaction(ctx, tx, 1)
 	if err != nil {
-		return fmt.Errorf("WaitForGenerateBlock error:%s", err)
+		return fmt.Errorf("WaitForSentTransaction error:%s", err)
 	}
 	return nil
 }
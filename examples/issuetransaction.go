@@ -5,6 +5,7 @@ import (
 	"DNA/common"
 	"DNA/core/transaction"
 	. "DNASDK"
+	"context"
 	"fmt"
 	"time"
 )
@@ -30,9 +31,11 @@ func IssueTransaction(client *DnaClient, controller, issuer *account.Account, as
 		return 	fmt.Errorf("SendTransaction error:%s\n", err)
 	}
 
-	_, err = client.WaitForGenerateBlock(time.Second * 30, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second * 30)
+	defer cancel()
+	_, _, err = client.WaitForSentTransaction(ctx, issueTx, 1)
 	if err != nil {
-		return fmt.Errorf("WaitForGenerateBlock error:%s\n", err)
+		return fmt.Errorf("WaitForSentTransaction error:%s\n", err)
 	}
 
 	return nil
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"DNA/common/log"
 	"DNA/core/asset"
 	. "DNASDK"
 	"flag"
@@ -22,6 +23,7 @@ func parseRpcAddress(rpcAddresses string) []string {
 }
 
 func main() {
+	log.Init(log.Stdout)
 	client := NewDnaClient(parseRpcAddress(DNAJsonRpcAddress))
 
 	walletClient := client.GetWalletClient("test")
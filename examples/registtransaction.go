@@ -5,6 +5,7 @@ import (
 	"DNA/common"
 	. "DNA/core/asset"
 	. "DNASDK"
+	"context"
 	"fmt"
 	"time"
 )
@@ -25,9 +26,11 @@ func RegisterTransaction(client *DnaClient, asset *Asset, amount common.Fixed64,
 		return common.Uint256{}, fmt.Errorf("SendTransaction AssetRegisterTransaction error:%s\n", err)
 	}
 
-	_, err = client.WaitForGenerateBlock(time.Second * 30, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second * 30)
+	defer cancel()
+	_, _, err = client.WaitForSentTransaction(ctx, regTx, 1)
 	if err != nil {
-		return common.Uint256{}, fmt.Errorf("WaitForGenerateBlock error:%s\n", err)
+		return common.Uint256{}, fmt.Errorf("WaitForSentTransaction error:%s\n", err)
 	}
 
 	return txHash, nil
@@ -5,6 +5,7 @@ import (
 	"DNA/account"
 	"DNA/core/transaction"
 	"DNASDK"
+	"context"
 	"fmt"
 	"time"
 )
@@ -76,9 +77,11 @@ func TransferTransaction(client *dnasdk.DnaClient, assetId common.Uint256, from,
 		return fmt.Errorf("SendTransaction error:%s", err)
 	}
 
-	_, err = client.WaitForGenerateBlock(time.Second * 30, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second * 30)
+	defer cancel()
+	_, _, err = client.WaitForSentTransaction(ctx, transferTx, 1)
 	if err != nil {
-		return fmt.Errorf("WaitForGenerateBlock error:%s", err)
+		return fmt.Errorf("WaitForSentTransaction error:%s", err)
 	}
 	return nil
 }
@@ -0,0 +1,182 @@
+package dnasdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DEFAULT_HEALTH_CHECK_INTERVAL is the interval of the health monitor started with a non-positive interval
+const DEFAULT_HEALTH_CHECK_INTERVAL = time.Second * 10
+
+// NodeStatus is the state of one rpc address seen by the health monitor
+type NodeStatus struct {
+	Address   string
+	Version   string
+	Height    uint32
+	Latency   time.Duration
+	Healthy   bool
+	Lagging   bool
+	LastError error
+	LastCheck time.Time
+}
+
+// healthMonitor call GetVersion and GetBlockCount on every endpoint periodically,
+// GetBlockCount only if the transport does not support GetVersion.
+// The endpoints more than maxLag blocks behind the best height are marked lagging,
+// and are not used for reads while another endpoint is in sync.
+type healthMonitor struct {
+	client   *DnaClient
+	interval time.Duration
+	maxLag   uint32
+	lock     sync.RWMutex
+	status   map[string]*NodeStatus
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// StartHealthMonitor start checking the nodes of rpcAddresses every interval in background.
+// Reads are only routed to the nodes within maxLag blocks of the best height.
+// DEFAULT_HEALTH_CHECK_INTERVAL is used if interval is not positive.
+func (this *DnaClient) StartHealthMonitor(interval time.Duration, maxLag uint32) {
+	if interval <= 0 {
+		interval = DEFAULT_HEALTH_CHECK_INTERVAL
+	}
+	this.StopHealthMonitor()
+	ctx, cancel := context.WithCancel(context.Background())
+	monitor := &healthMonitor{
+		client:   this,
+		interval: interval,
+		maxLag:   maxLag,
+		status:   make(map[string]*NodeStatus),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	this.monitorLock.Lock()
+	this.monitor = monitor
+	this.monitorLock.Unlock()
+	go monitor.run(ctx)
+}
+
+// StopHealthMonitor stop the health monitor, and all endpoints can be used for reads again
+func (this *DnaClient) StopHealthMonitor() {
+	this.monitorLock.Lock()
+	monitor := this.monitor
+	this.monitor = nil
+	this.monitorLock.Unlock()
+	if monitor == nil {
+		return
+	}
+	monitor.cancel()
+	<-monitor.done
+	for _, endpoint := range this.endpoints.all() {
+		endpoint.setLagging(false)
+	}
+}
+
+// GetNodeStatus return the status of every node checked by the health monitor,
+// nil if the health monitor is not started
+func (this *DnaClient) GetNodeStatus() []*NodeStatus {
+	this.monitorLock.RLock()
+	monitor := this.monitor
+	this.monitorLock.RUnlock()
+	if monitor == nil {
+		return nil
+	}
+	return monitor.getStatus()
+}
+
+func (this *healthMonitor) run(ctx context.Context) {
+	defer close(this.done)
+	ticker := time.NewTicker(this.interval)
+	defer ticker.Stop()
+	for {
+		this.checkAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (this *healthMonitor) checkAll(ctx context.Context) {
+	endpoints := this.client.endpoints.all()
+	statuses := make([]*NodeStatus, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint *Endpoint) {
+			defer wg.Done()
+			statuses[i] = this.check(ctx, endpoint)
+		}(i, endpoint)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	bestHeight := uint32(0)
+	for _, status := range statuses {
+		if status.Healthy && status.Height > bestHeight {
+			bestHeight = status.Height
+		}
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for i, status := range statuses {
+		status.Lagging = status.Healthy && bestHeight-status.Height > this.maxLag
+		endpoints[i].setLagging(status.Lagging)
+		this.status[status.Address] = status
+	}
+}
+
+func (this *healthMonitor) check(ctx context.Context, endpoint *Endpoint) *NodeStatus {
+	ctx, cancel := context.WithTimeout(ctx, this.interval)
+	defer cancel()
+	status := &NodeStatus{
+		Address:   endpoint.Address,
+		LastCheck: time.Now(),
+	}
+	start := time.Now()
+	version, err := this.client.callEndpoint(ctx, endpoint, DNA_RPC_GETVERSION, []interface{}{})
+	switch {
+	case errors.Is(err, ErrUnsupported):
+		//the transport cannot get version, like RestTransport, the block count is enough
+	case err != nil:
+		status.LastError = fmt.Errorf("GetVersion error:%w", err)
+		return status
+	default:
+		status.Latency = time.Since(start)
+		status.Version = string(version)
+	}
+	start = time.Now()
+	_, err = this.client.callEndpoint(ctx, endpoint, DNA_RPC_GETBLOCKCOUNT, []interface{}{})
+	if err != nil {
+		status.LastError = fmt.Errorf("GetBlockCount error:%w", err)
+		return status
+	}
+	if status.Latency == 0 {
+		status.Latency = time.Since(start)
+	}
+	status.Height = endpoint.Height()
+	status.Healthy = true
+	return status
+}
+
+func (this *healthMonitor) getStatus() []*NodeStatus {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	statuses := make([]*NodeStatus, 0, len(this.status))
+	for _, endpoint := range this.client.endpoints.all() {
+		status, ok := this.status[endpoint.Address]
+		if !ok {
+			continue
+		}
+		s := *status
+		statuses = append(statuses, &s)
+	}
+	return statuses
+}
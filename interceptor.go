@@ -0,0 +1,137 @@
+package dnasdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoResponse is returned when a transport or an interceptor return neither a response nor an error
+var ErrNoResponse = errors.New("no response")
+
+// RpcInvoker send req to the node at address
+type RpcInvoker func(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error)
+
+// RpcInterceptor is a middleware around the round trip of a request. It see the method,
+// params and qid in req, and the raw response, result, error and latency in the response.
+// It may rewrite req before calling invoker, or change the response returned.
+// The returned error means the node cannot be reached, as Transport.RoundTrip.
+type RpcInterceptor func(ctx context.Context, address string, req *RpcRequest, invoker RpcInvoker) (*RpcResponse, error)
+
+// BatchInvoker send reqs to the node at address in one batch request
+type BatchInvoker func(ctx context.Context, address string, reqs []*RpcRequest) ([]*RpcResponse, error)
+
+// BatchInterceptor is a middleware around the round trip of a batch request sent by BatchCall,
+// as RpcInterceptor for one request. The responses returned must be in the order of reqs,
+// a nil response is the ErrNoResponse error of its request.
+type BatchInterceptor func(ctx context.Context, address string, reqs []*RpcRequest, invoker BatchInvoker) ([]*RpcResponse, error)
+
+// AddInterceptor append interceptors to the chain, the first added is the outermost.
+// Batch requests sent by BatchCall are seen by the interceptors added by AddBatchInterceptor,
+// or by these interceptors one by one if the transport is not a BatchTransport.
+func (this *DnaClient) AddInterceptor(interceptors ...RpcInterceptor) {
+	this.interceptorLock.Lock()
+	defer this.interceptorLock.Unlock()
+	this.interceptors = append(this.interceptors, interceptors...)
+}
+
+// AddBatchInterceptor append interceptors to the chain of batch requests, the first added is the outermost
+func (this *DnaClient) AddBatchInterceptor(interceptors ...BatchInterceptor) {
+	this.interceptorLock.Lock()
+	defer this.interceptorLock.Unlock()
+	this.batchInterceptors = append(this.batchInterceptors, interceptors...)
+}
+
+func (this *DnaClient) invoke(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	this.interceptorLock.RLock()
+	interceptors := this.interceptors
+	this.interceptorLock.RUnlock()
+
+	invoker := this.invokeTransport
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		invoker = chainInterceptor(interceptors[i], invoker)
+	}
+	res, err := invoker(ctx, address, req)
+	if err == nil && res == nil {
+		return nil, fmt.Errorf("%w method:%s address:%s", ErrNoResponse, req.Method, address)
+	}
+	return res, err
+}
+
+func chainInterceptor(interceptor RpcInterceptor, next RpcInvoker) RpcInvoker {
+	return func(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+		return interceptor(ctx, address, req, next)
+	}
+}
+
+func (this *DnaClient) invokeBatch(ctx context.Context, batchTransport BatchTransport, address string, reqs []*RpcRequest) ([]*RpcResponse, error) {
+	this.interceptorLock.RLock()
+	interceptors := this.batchInterceptors
+	this.interceptorLock.RUnlock()
+
+	invoker := func(ctx context.Context, address string, reqs []*RpcRequest) ([]*RpcResponse, error) {
+		return this.invokeBatchTransport(ctx, batchTransport, address, reqs)
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		invoker = chainBatchInterceptor(interceptors[i], invoker)
+	}
+	ress, err := invoker(ctx, address, reqs)
+	if err != nil {
+		return nil, err
+	}
+	if len(ress) != len(reqs) {
+		return nil, fmt.Errorf("batch responses:%d requests:%d", len(ress), len(reqs))
+	}
+	for i, res := range ress {
+		if res == nil {
+			ress[i] = &RpcResponse{Error: fmt.Errorf("%w method:%s index:%d address:%s", ErrNoResponse, reqs[i].Method, i, address)}
+		}
+	}
+	return ress, nil
+}
+
+func chainBatchInterceptor(interceptor BatchInterceptor, next BatchInvoker) BatchInvoker {
+	return func(ctx context.Context, address string, reqs []*RpcRequest) ([]*RpcResponse, error) {
+		return interceptor(ctx, address, reqs, next)
+	}
+}
+
+func (this *DnaClient) invokeBatchTransport(ctx context.Context, batchTransport BatchTransport, address string, reqs []*RpcRequest) ([]*RpcResponse, error) {
+	start := time.Now()
+	ress, err := batchTransport.BatchRoundTrip(ctx, address, reqs)
+	latency := time.Since(start)
+	fields := []Field{MethodField("batch"), EndpointField(address), DurationField(latency)}
+	if err != nil {
+		this.logger.Debug("rpc batch request failed", append(fields, ErrorField(err))...)
+		return nil, err
+	}
+	for _, res := range ress {
+		if res != nil {
+			res.Latency = latency
+		}
+	}
+	this.logger.Debug("rpc batch request", fields...)
+	return ress, nil
+}
+
+func (this *DnaClient) invokeTransport(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	start := time.Now()
+	res, err := this.transport.RoundTrip(ctx, address, req)
+	latency := time.Since(start)
+	if err == nil && res == nil {
+		err = fmt.Errorf("%w method:%s address:%s", ErrNoResponse, req.Method, address)
+	}
+	fields := []Field{MethodField(req.Method), QidField(req.Qid), EndpointField(address), DurationField(latency)}
+	switch {
+	case err != nil:
+		this.logger.Debug("rpc request failed", append(fields, ErrorField(err))...)
+	case res.Error != nil:
+		res.Latency = latency
+		this.logger.Debug("rpc request error", append(fields, ErrorField(res.Error))...)
+	default:
+		res.Latency = latency
+		this.logger.Debug("rpc request", fields...)
+	}
+	return res, err
+}
@@ -0,0 +1,91 @@
+package dnasdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// funcTransport is a Transport answering with a function, as a node
+type funcTransport func(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error)
+
+func (this funcTransport) RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	return this(ctx, address, req)
+}
+
+// funcBatchTransport is a funcTransport sending batches one by one
+type funcBatchTransport struct {
+	funcTransport
+}
+
+func (this funcBatchTransport) BatchRoundTrip(ctx context.Context, address string, reqs []*RpcRequest) ([]*RpcResponse, error) {
+	ress := make([]*RpcResponse, len(reqs))
+	for i, req := range reqs {
+		res, err := this.RoundTrip(ctx, address, req)
+		if err != nil {
+			return nil, err
+		}
+		ress[i] = res
+	}
+	return ress, nil
+}
+
+// resultResponse is the response of a node returning result
+func resultResponse(result string) *RpcResponse {
+	return &RpcResponse{Raw: []byte(result), Result: []byte(result)}
+}
+
+func TestNilTransportResponse(t *testing.T) {
+	client, err := NewDnaClientWithOptions([]string{"http://node"},
+		WithTransport(funcTransport(func(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+			return nil, nil
+		})),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 1}),
+	)
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	_, err = client.GetBlockCount()
+	if !errors.Is(err, ErrNoResponse) {
+		t.Fatalf("GetBlockCount error:%v expected ErrNoResponse", err)
+	}
+}
+
+func TestNilInterceptorResponse(t *testing.T) {
+	client, err := NewDnaClientWithOptions([]string{"http://node"},
+		WithTransport(funcBatchTransport{func(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+			return resultResponse("7"), nil
+		}}),
+		WithInterceptors(func(ctx context.Context, address string, req *RpcRequest, invoker RpcInvoker) (*RpcResponse, error) {
+			return nil, nil
+		}),
+		WithBatchInterceptors(func(ctx context.Context, address string, reqs []*RpcRequest, invoker BatchInvoker) ([]*RpcResponse, error) {
+			ress, err := invoker(ctx, address, reqs)
+			if err != nil {
+				return nil, err
+			}
+			ress[0] = nil
+			return ress, nil
+		}),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 1}),
+	)
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	_, err = client.GetBlockCount()
+	if !errors.Is(err, ErrNoResponse) {
+		t.Fatalf("GetBlockCount error:%v expected ErrNoResponse", err)
+	}
+
+	elems := []*BatchElem{{Method: DNA_RPC_GETBLOCKCOUNT}, {Method: DNA_RPC_GETBLOCKCOUNT}}
+	err = client.BatchCall(elems)
+	if err != nil {
+		t.Fatalf("BatchCall error:%s", err)
+	}
+	if !errors.Is(elems[0].Error, ErrNoResponse) {
+		t.Fatalf("BatchCall elem error:%v expected ErrNoResponse", elems[0].Error)
+	}
+	if elems[1].Error != nil || string(elems[1].Result) != "7" {
+		t.Fatalf("BatchCall elem result:%s error:%v expected:7", elems[1].Result, elems[1].Error)
+	}
+}
@@ -0,0 +1,72 @@
+package dnasdk
+
+import (
+	"DNA/core/ledger"
+	"context"
+)
+
+// DEFAULT_ITERATE_WORKERS is used by IterateBlocks when workers is not positive
+const DEFAULT_ITERATE_WORKERS = 4
+
+// BlockResult is a block delivered by IterateBlocks, Err is the error of getting this block
+type BlockResult struct {
+	Height uint32
+	Block  *ledger.Block
+	Err    error
+}
+
+// IterateBlocks get the blocks from start to end, both included, with at most workers
+// requests at the same time. The results are delivered in height order, an error of a
+// block does not stop the iteration. The channel is closed after end, or when ctx is done,
+// cancel ctx to stop early.
+func (this *DnaClient) IterateBlocks(ctx context.Context, start, end uint32, workers int) <-chan *BlockResult {
+	if workers <= 0 {
+		workers = DEFAULT_ITERATE_WORKERS
+	}
+	out := make(chan *BlockResult)
+	pending := make(chan chan *BlockResult, workers)
+	sem := make(chan struct{}, workers)
+
+	go func() {
+		defer close(pending)
+		for height := start; height <= end; height++ {
+			res := make(chan *BlockResult, 1)
+			select {
+			case pending <- res:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			go func(height uint32) {
+				defer func() { <-sem }()
+				block, err := this.GetBlockByHeightContext(ctx, height)
+				res <- &BlockResult{Height: height, Block: block, Err: err}
+			}(height)
+			if height == end {
+				break
+			}
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		for res := range pending {
+			var result *BlockResult
+			select {
+			case result = <-res:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
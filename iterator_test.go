@@ -0,0 +1,135 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blocksTransport serve getblock with empty blocks, the lower blocks are answered later so that
+// they complete out of order. The heights in missing are unknown by node.
+type blocksTransport struct {
+	lock    sync.Mutex
+	missing map[uint32]bool
+	running int
+	//maxRunning is the max count of requests at the same time
+	maxRunning int
+}
+
+func (this *blocksTransport) RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	this.lock.Lock()
+	this.running++
+	if this.running > this.maxRunning {
+		this.maxRunning = this.running
+	}
+	this.lock.Unlock()
+	defer func() {
+		this.lock.Lock()
+		this.running--
+		this.lock.Unlock()
+	}()
+
+	height := req.Params[0].(uint32)
+	select {
+	case <-time.After(time.Millisecond * time.Duration(10-height%10)):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if this.missing[height] {
+		return resultResponse(DnaRpcNil), nil
+	}
+	data, err := json.Marshal(&BlockInfo{
+		BlockData: &BlockHead{
+			PrevBlockHash:    Uint256ToString(Uint256{}),
+			TransactionsRoot: Uint256ToString(Uint256{}),
+			NextBookKeeper:   Uint160ToString(Uint160{}),
+			Height:           height,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resultResponse(string(data)), nil
+}
+
+func newBlocksClient(t *testing.T, transport *blocksTransport) *DnaClient {
+	client, err := NewDnaClientWithOptions([]string{"http://node"}, WithTransport(transport))
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	return client
+}
+
+func TestIterateBlocks(t *testing.T) {
+	transport := &blocksTransport{missing: map[uint32]bool{5: true}}
+	client := newBlocksClient(t, transport)
+
+	height := uint32(2)
+	for result := range client.IterateBlocks(context.Background(), 2, 12, 3) {
+		if result.Height != height {
+			t.Fatalf("IterateBlocks height:%d expected:%d", result.Height, height)
+		}
+		if height == 5 {
+			if !errors.Is(result.Err, ErrUnknownBlock) {
+				t.Fatalf("IterateBlocks height:%d error:%v expected ErrUnknownBlock", height, result.Err)
+			}
+		} else {
+			if result.Err != nil {
+				t.Fatalf("IterateBlocks height:%d error:%s", height, result.Err)
+			}
+			if result.Block.Blockdata.Height != height {
+				t.Fatalf("IterateBlocks block height:%d expected:%d", result.Block.Blockdata.Height, height)
+			}
+		}
+		height++
+	}
+	if height != 13 {
+		t.Fatalf("IterateBlocks stopped at:%d expected:13", height)
+	}
+	if transport.maxRunning > 3 {
+		t.Fatalf("IterateBlocks requests at the same time:%d expected at most 3", transport.maxRunning)
+	}
+}
+
+func TestIterateBlocksSingle(t *testing.T) {
+	client := newBlocksClient(t, &blocksTransport{})
+	results := make([]*BlockResult, 0)
+	for result := range client.IterateBlocks(context.Background(), 7, 7, 0) {
+		results = append(results, result)
+	}
+	if len(results) != 1 || results[0].Height != 7 || results[0].Err != nil {
+		t.Fatalf("IterateBlocks of one block:%+v expected height 7", results)
+	}
+}
+
+func TestIterateBlocksCancel(t *testing.T) {
+	client := newBlocksClient(t, &blocksTransport{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := client.IterateBlocks(ctx, 0, 1000, 2)
+	for i := 0; i < 2; i++ {
+		<-results
+	}
+	cancel()
+	count := 0
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-results:
+			if !ok {
+				if count > 2 {
+					t.Fatalf("IterateBlocks delivered:%d blocks after cancel", count)
+				}
+				return
+			}
+			count++
+		case <-timeout:
+			t.Fatalf("IterateBlocks channel not closed after cancel")
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package dnasdk
+
+import (
+	"DNA/common/log"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Field keys used by DnaClient
+const (
+	LOG_FIELD_METHOD   = "method"
+	LOG_FIELD_QID      = "qid"
+	LOG_FIELD_ENDPOINT = "endpoint"
+	LOG_FIELD_TXHASH   = "txhash"
+	LOG_FIELD_DURATION = "duration"
+	LOG_FIELD_ATTEMPT  = "attempt"
+	LOG_FIELD_ERROR    = "error"
+)
+
+// Field is a key value pair attached to a log
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func MethodField(method string) Field {
+	return Field{Key: LOG_FIELD_METHOD, Value: method}
+}
+
+func QidField(qid string) Field {
+	return Field{Key: LOG_FIELD_QID, Value: qid}
+}
+
+func EndpointField(address string) Field {
+	return Field{Key: LOG_FIELD_ENDPOINT, Value: address}
+}
+
+func TxHashField(txHash string) Field {
+	return Field{Key: LOG_FIELD_TXHASH, Value: txHash}
+}
+
+func DurationField(duration time.Duration) Field {
+	return Field{Key: LOG_FIELD_DURATION, Value: duration}
+}
+
+func ErrorField(err error) Field {
+	return Field{Key: LOG_FIELD_ERROR, Value: err}
+}
+
+// Logger receive the structured logs of DnaClient
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+// NopLogger drop all logs, it is the default Logger of DnaClient
+type NopLogger struct{}
+
+func (this NopLogger) Debug(msg string, fields ...Field) {}
+func (this NopLogger) Info(msg string, fields ...Field)  {}
+func (this NopLogger) Warn(msg string, fields ...Field)  {}
+func (this NopLogger) Error(msg string, fields ...Field) {}
+
+// SlogLogger write logs to a log/slog Logger, fields become the attributes
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	return &SlogLogger{logger: logger}
+}
+
+func (this *SlogLogger) Debug(msg string, fields ...Field) {
+	this.log(slog.LevelDebug, msg, fields)
+}
+
+func (this *SlogLogger) Info(msg string, fields ...Field) {
+	this.log(slog.LevelInfo, msg, fields)
+}
+
+func (this *SlogLogger) Warn(msg string, fields ...Field) {
+	this.log(slog.LevelWarn, msg, fields)
+}
+
+func (this *SlogLogger) Error(msg string, fields ...Field) {
+	this.log(slog.LevelError, msg, fields)
+}
+
+func (this *SlogLogger) log(level slog.Level, msg string, fields []Field) {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+	this.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// DNALogger write logs with the DNA log package. The application should call log.Init before use it.
+type DNALogger struct{}
+
+func (this DNALogger) Debug(msg string, fields ...Field) { log.Debug(formatLog(msg, fields)) }
+func (this DNALogger) Info(msg string, fields ...Field)  { log.Info(formatLog(msg, fields)) }
+func (this DNALogger) Warn(msg string, fields ...Field)  { log.Warn(formatLog(msg, fields)) }
+func (this DNALogger) Error(msg string, fields ...Field) { log.Error(formatLog(msg, fields)) }
+
+func formatLog(msg string, fields []Field) string {
+	for _, field := range fields {
+		msg += fmt.Sprintf(" %s:%v", field.Key, field.Value)
+	}
+	return msg
+}
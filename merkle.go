@@ -0,0 +1,243 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"DNA/core/ledger"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MAX_MERKLE_BRANCH is the max length of a deserialized branch, enough for 2^32 transactions
+const MAX_MERKLE_BRANCH = 32
+
+// ErrInvalidMerkleProof is returned when a MerkleProof does not lead to the expected root
+var ErrInvalidMerkleProof = errors.New("invalid merkle proof")
+
+// MerkleProof prove transaction TxHash is at Index of the block at Height, whose TransactionsRoot
+// is given. Branch is the sibling hashes from the leaves to the root.
+type MerkleProof struct {
+	TxHash           Uint256
+	Height           uint32
+	TransactionsRoot Uint256
+	Index            uint32
+	Branch           []Uint256
+}
+
+// BuildMerkleProof build the proof of txHash in block. The tree is the one of DNA, a node is
+// the double SHA256 of its children, the last node of an odd level is paired with itself.
+func BuildMerkleProof(block *ledger.Block, txHash Uint256) (*MerkleProof, error) {
+	if block == nil || block.Blockdata == nil {
+		return nil, fmt.Errorf("block is nil")
+	}
+	level := make([]Uint256, 0, len(block.Transactions))
+	index := -1
+	for i, tx := range block.Transactions {
+		hash := ComputeTransactionHash(tx)
+		if hash == txHash {
+			index = i
+		}
+		level = append(level, hash)
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("%w TxHash:%x height:%d", ErrUnknownTransaction, txHash.ToArray(), block.Blockdata.Height)
+	}
+
+	proof := &MerkleProof{
+		TxHash:           txHash,
+		Height:           block.Blockdata.Height,
+		TransactionsRoot: block.Blockdata.TransactionsRoot,
+		Index:            uint32(index),
+		Branch:           make([]Uint256, 0),
+	}
+	for pos := index; len(level) > 1; pos /= 2 {
+		sibling := pos ^ 1
+		if sibling >= len(level) {
+			sibling = pos
+		}
+		proof.Branch = append(proof.Branch, level[sibling])
+
+		next := make([]Uint256, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, merkleParent(level[i], level[i+1]))
+			} else {
+				next = append(next, merkleParent(level[i], level[i]))
+			}
+		}
+		level = next
+	}
+	if level[0] != block.Blockdata.TransactionsRoot {
+		return nil, fmt.Errorf("%w computed root:%x TransactionsRoot:%x height:%d", ErrInvalidMerkleProof,
+			level[0].ToArray(), block.Blockdata.TransactionsRoot.ToArray(), block.Blockdata.Height)
+	}
+	return proof, nil
+}
+
+// ComputeRoot return the merkle root computed from TxHash along Branch. Index must be in the
+// 2^len(Branch) leaves of the tree, or the high bits would be ignored.
+func (this *MerkleProof) ComputeRoot() (Uint256, error) {
+	if len(this.Branch) > MAX_MERKLE_BRANCH {
+		return Uint256{}, fmt.Errorf("%w branch length:%d", ErrInvalidMerkleProof, len(this.Branch))
+	}
+	if len(this.Branch) < MAX_MERKLE_BRANCH && uint64(this.Index) >= uint64(1)<<uint(len(this.Branch)) {
+		return Uint256{}, fmt.Errorf("%w index:%d out of branch length:%d", ErrInvalidMerkleProof, this.Index, len(this.Branch))
+	}
+	hash := this.TxHash
+	index := this.Index
+	for _, sibling := range this.Branch {
+		if index%2 == 0 {
+			hash = merkleParent(hash, sibling)
+		} else {
+			hash = merkleParent(sibling, hash)
+		}
+		index /= 2
+	}
+	return hash, nil
+}
+
+// Verify check the proof leads to root, the TransactionsRoot of a trusted block header
+func (this *MerkleProof) Verify(root Uint256) error {
+	if this.TransactionsRoot != root {
+		return fmt.Errorf("%w TransactionsRoot:%x expected:%x", ErrInvalidMerkleProof, this.TransactionsRoot.ToArray(), root.ToArray())
+	}
+	computed, err := this.ComputeRoot()
+	if err != nil {
+		return err
+	}
+	if computed != root {
+		return fmt.Errorf("%w computed root:%x expected:%x", ErrInvalidMerkleProof, computed.ToArray(), root.ToArray())
+	}
+	return nil
+}
+
+// VerifyMerkleProof check proof against the TransactionsRoot of header
+func VerifyMerkleProof(proof *MerkleProof, header *ledger.Blockdata) error {
+	if proof.Height != header.Height {
+		return fmt.Errorf("%w height:%d expected:%d", ErrInvalidMerkleProof, proof.Height, header.Height)
+	}
+	return proof.Verify(header.TransactionsRoot)
+}
+
+func merkleParent(left, right Uint256) Uint256 {
+	data := make([]byte, 0, 64)
+	data = append(data, left.ToArray()...)
+	data = append(data, right.ToArray()...)
+	temp := sha256.Sum256(data)
+	return Uint256(sha256.Sum256(temp[:]))
+}
+
+type merkleProofInfo struct {
+	TxHash           string
+	Height           uint32
+	TransactionsRoot string
+	Index            uint32
+	Branch           []string
+}
+
+func (this *MerkleProof) MarshalJSON() ([]byte, error) {
+	info := &merkleProofInfo{
+		TxHash:           Uint256ToString(this.TxHash),
+		Height:           this.Height,
+		TransactionsRoot: Uint256ToString(this.TransactionsRoot),
+		Index:            this.Index,
+		Branch:           make([]string, 0, len(this.Branch)),
+	}
+	for _, hash := range this.Branch {
+		info.Branch = append(info.Branch, Uint256ToString(hash))
+	}
+	return json.Marshal(info)
+}
+
+func (this *MerkleProof) UnmarshalJSON(data []byte) error {
+	info := &merkleProofInfo{}
+	err := json.Unmarshal(data, info)
+	if err != nil {
+		return err
+	}
+	txHash, err := ParseUint256FromString(info.TxHash)
+	if err != nil {
+		return fmt.Errorf("TxHash ParseUint256FromString:%s error:%w", info.TxHash, err)
+	}
+	root, err := ParseUint256FromString(info.TransactionsRoot)
+	if err != nil {
+		return fmt.Errorf("TransactionsRoot ParseUint256FromString:%s error:%w", info.TransactionsRoot, err)
+	}
+	branch := make([]Uint256, 0, len(info.Branch))
+	for _, hashStr := range info.Branch {
+		hash, err := ParseUint256FromString(hashStr)
+		if err != nil {
+			return fmt.Errorf("Branch ParseUint256FromString:%s error:%w", hashStr, err)
+		}
+		branch = append(branch, hash)
+	}
+	this.TxHash = txHash
+	this.Height = info.Height
+	this.TransactionsRoot = root
+	this.Index = info.Index
+	this.Branch = branch
+	return nil
+}
+
+// Serialize write the proof in binary: TxHash, Height, TransactionsRoot, Index, branch length
+// and Branch, integers are uint32 little endian
+func (this *MerkleProof) Serialize(w io.Writer) error {
+	buf := &bytes.Buffer{}
+	buf.Write(this.TxHash.ToArray())
+	binary.Write(buf, binary.LittleEndian, this.Height)
+	buf.Write(this.TransactionsRoot.ToArray())
+	binary.Write(buf, binary.LittleEndian, this.Index)
+	binary.Write(buf, binary.LittleEndian, uint32(len(this.Branch)))
+	for _, hash := range this.Branch {
+		buf.Write(hash.ToArray())
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Deserialize read the proof written by Serialize
+func (this *MerkleProof) Deserialize(r io.Reader) error {
+	err := this.TxHash.Deserialize(r)
+	if err != nil {
+		return fmt.Errorf("TxHash Deserialize error:%w", err)
+	}
+	err = binary.Read(r, binary.LittleEndian, &this.Height)
+	if err != nil {
+		return fmt.Errorf("Height Deserialize error:%w", err)
+	}
+	err = this.TransactionsRoot.Deserialize(r)
+	if err != nil {
+		return fmt.Errorf("TransactionsRoot Deserialize error:%w", err)
+	}
+	err = binary.Read(r, binary.LittleEndian, &this.Index)
+	if err != nil {
+		return fmt.Errorf("Index Deserialize error:%w", err)
+	}
+	var count uint32
+	err = binary.Read(r, binary.LittleEndian, &count)
+	if err != nil {
+		return fmt.Errorf("Branch length Deserialize error:%w", err)
+	}
+	if count > MAX_MERKLE_BRANCH {
+		return fmt.Errorf("%w branch length:%d", ErrInvalidMerkleProof, count)
+	}
+	this.Branch = make([]Uint256, count)
+	for i := range this.Branch {
+		err = this.Branch[i].Deserialize(r)
+		if err != nil {
+			return fmt.Errorf("Branch Deserialize error:%w", err)
+		}
+	}
+	return nil
+}
+
+// ToArray return the binary of Serialize
+func (this *MerkleProof) ToArray() []byte {
+	buf := &bytes.Buffer{}
+	this.Serialize(buf)
+	return buf.Bytes()
+}
@@ -0,0 +1,48 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"errors"
+	"testing"
+)
+
+func TestMerkleProofIndex(t *testing.T) {
+	//the tree of 3 transactions, c is paired with itself
+	a, b, c := Uint256{1}, Uint256{2}, Uint256{3}
+	ab, cc := merkleParent(a, b), merkleParent(c, c)
+	root := merkleParent(ab, cc)
+
+	cases := []struct {
+		name   string
+		txHash Uint256
+		index  uint32
+		branch []Uint256
+		valid  bool
+	}{
+		{name: "first", txHash: a, index: 0, branch: []Uint256{b, cc}, valid: true},
+		{name: "second", txHash: b, index: 1, branch: []Uint256{a, cc}, valid: true},
+		{name: "odd last", txHash: c, index: 2, branch: []Uint256{c, ab}, valid: true},
+		{name: "wrong index", txHash: a, index: 1, branch: []Uint256{b, cc}},
+		{name: "index of the branch length", txHash: a, index: 4, branch: []Uint256{b, cc}},
+		//5 is 1 in the low bits, it would lead to root without the range check
+		{name: "index aliasing second", txHash: b, index: 5, branch: []Uint256{a, cc}},
+		{name: "max index", txHash: a, index: ^uint32(0), branch: []Uint256{b, cc}},
+		{name: "no branch", txHash: root, index: 1, branch: []Uint256{}},
+		{name: "branch too long", txHash: a, index: 0, branch: make([]Uint256, MAX_MERKLE_BRANCH+1)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			proof := &MerkleProof{TxHash: c.txHash, TransactionsRoot: root, Index: c.index, Branch: c.branch}
+			err := proof.Verify(root)
+			if c.valid {
+				if err != nil {
+					t.Fatalf("Verify error:%s", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidMerkleProof) {
+				t.Fatalf("Verify error:%v expected ErrInvalidMerkleProof", err)
+			}
+		})
+	}
+}
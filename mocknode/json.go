@@ -0,0 +1,98 @@
+package mocknode
+
+import (
+	"DNA/core/contract/program"
+	"DNA/core/ledger"
+	"DNA/core/transaction"
+	. "DNASDK"
+	"encoding/hex"
+)
+
+func programInfo(p *program.Program) ProgramInfo {
+	if p == nil {
+		return ProgramInfo{}
+	}
+	return ProgramInfo{
+		Code:      hex.EncodeToString(p.Code),
+		Parameter: hex.EncodeToString(p.Parameter),
+	}
+}
+
+func transactionInfo(tx *transaction.Transaction) (*Transactions, error) {
+	payloadData, err := EncodePayload(tx.TxType, tx.Payload)
+	if err != nil {
+		return nil, err
+	}
+	txInfo := &Transactions{
+		TxType:         tx.TxType,
+		PayloadVersion: tx.PayloadVersion,
+		Payload:        payloadData,
+		Attributes:     make([]TxAttributeInfo, 0, len(tx.Attributes)),
+		UTXOInputs:     make([]UTXOTxInputInfo, 0, len(tx.UTXOInputs)),
+		BalanceInputs:  make([]BalanceTxInputInfo, 0, len(tx.BalanceInputs)),
+		Outputs:        make([]TxoutputInfo, 0, len(tx.Outputs)),
+		Programs:       make([]ProgramInfo, 0, len(tx.Programs)),
+		Hash:           Uint256ToString(tx.Hash()),
+	}
+	for _, attr := range tx.Attributes {
+		txInfo.Attributes = append(txInfo.Attributes, TxAttributeInfo{
+			Usage: byte(attr.Usage),
+			Data:  hex.EncodeToString(attr.Data),
+		})
+	}
+	for _, input := range tx.UTXOInputs {
+		txInfo.UTXOInputs = append(txInfo.UTXOInputs, UTXOTxInputInfo{
+			ReferTxID:          Uint256ToString(input.ReferTxID),
+			ReferTxOutputIndex: input.ReferTxOutputIndex,
+		})
+	}
+	for _, input := range tx.BalanceInputs {
+		txInfo.BalanceInputs = append(txInfo.BalanceInputs, BalanceTxInputInfo{
+			AssetID:     Uint256ToString(input.AssetID),
+			Value:       input.Value,
+			ProgramHash: Uint160ToString(input.ProgramHash),
+		})
+	}
+	for _, output := range tx.Outputs {
+		txInfo.Outputs = append(txInfo.Outputs, outputInfo(output))
+	}
+	for _, p := range tx.Programs {
+		txInfo.Programs = append(txInfo.Programs, programInfo(p))
+	}
+	return txInfo, nil
+}
+
+func outputInfo(output *transaction.TxOutput) TxoutputInfo {
+	return TxoutputInfo{
+		AssetID:     Uint256ToString(output.AssetID),
+		Value:       output.Value,
+		ProgramHash: Uint160ToString(output.ProgramHash),
+	}
+}
+
+func blockInfo(block *ledger.Block) (*BlockInfo, error) {
+	hash := Uint256ToString(block.Blockdata.Hash())
+	info := &BlockInfo{
+		Hash: hash,
+		BlockData: &BlockHead{
+			Version:          block.Blockdata.Version,
+			PrevBlockHash:    Uint256ToString(block.Blockdata.PrevBlockHash),
+			TransactionsRoot: Uint256ToString(block.Blockdata.TransactionsRoot),
+			Timestamp:        block.Blockdata.Timestamp,
+			Height:           block.Blockdata.Height,
+			ConsensusData:    block.Blockdata.ConsensusData,
+			NextBookKeeper:   Uint160ToString(block.Blockdata.NextBookKeeper),
+			Program:          programInfo(block.Blockdata.Program),
+			Hash:             hash,
+		},
+		Transactions: make([]*Transactions, 0, len(block.Transactions)),
+	}
+	for _, tx := range block.Transactions {
+		txInfo, err := transactionInfo(tx)
+		if err != nil {
+			return nil, err
+		}
+		info.Transactions = append(info.Transactions, txInfo)
+	}
+	return info, nil
+}
@@ -0,0 +1,244 @@
+package mocknode
+
+import (
+	. "DNA/common"
+	"DNA/core/contract/program"
+	"DNA/core/ledger"
+	"DNA/core/transaction"
+	"DNA/core/transaction/payload"
+	"DNA/crypto"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	errDuplicatedTx   = errors.New("duplicated transaction")
+	errUnknownInput   = errors.New("unknown utxo input")
+	errSpentInput     = errors.New("utxo input already spent")
+	errUnknownAsset   = errors.New("unknown asset")
+	errUnbalanced     = errors.New("inputs and outputs are unbalanced")
+	errAmountExceeded = errors.New("issued amount exceeds registered amount")
+)
+
+type utxoKey struct {
+	txId  Uint256
+	index uint16
+}
+
+// memLedger is the in-memory chain of Node. Transactions are checked against
+// the UTXO set, but signatures are not verified.
+type memLedger struct {
+	lock       sync.RWMutex
+	blocks     []*ledger.Block
+	blockIndex map[Uint256]uint32
+	txs        map[Uint256]*transaction.Transaction
+	utxos      map[utxoKey]*transaction.TxOutput
+	issued     map[Uint256]Fixed64
+	identities map[string][]byte
+	pool       []*transaction.Transaction
+	poolSpent  map[utxoKey]bool
+	lastTime   uint32
+}
+
+func newMemLedger() *memLedger {
+	l := &memLedger{
+		blockIndex: make(map[Uint256]uint32),
+		txs:        make(map[Uint256]*transaction.Transaction),
+		utxos:      make(map[utxoKey]*transaction.TxOutput),
+		issued:     make(map[Uint256]Fixed64),
+		identities: make(map[string][]byte),
+		poolSpent:  make(map[utxoKey]bool),
+	}
+	l.generateBlock()
+	return l
+}
+
+func (this *memLedger) height() uint32 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return uint32(len(this.blocks))
+}
+
+func (this *memLedger) getBlock(height uint32) *ledger.Block {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if height >= uint32(len(this.blocks)) {
+		return nil
+	}
+	return this.blocks[height]
+}
+
+func (this *memLedger) getBlockByHash(hash Uint256) *ledger.Block {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	height, ok := this.blockIndex[hash]
+	if !ok {
+		return nil
+	}
+	return this.blocks[height]
+}
+
+func (this *memLedger) getTransaction(txHash Uint256) *transaction.Transaction {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.txs[txHash]
+}
+
+func (this *memLedger) getIdentity(did string) ([]byte, bool) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	ddo, ok := this.identities[did]
+	return ddo, ok
+}
+
+func (this *memLedger) getUnspents(programHash Uint160, assetId Uint256) map[utxoKey]*transaction.TxOutput {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	unspents := make(map[utxoKey]*transaction.TxOutput)
+	for k, output := range this.utxos {
+		if output.ProgramHash == programHash && output.AssetID == assetId {
+			unspents[k] = output
+		}
+	}
+	return unspents
+}
+
+// appendTransaction check tx against the ledger and the pool, and add it to the pool
+func (this *memLedger) appendTransaction(tx *transaction.Transaction) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	txHash := tx.Hash()
+	if _, ok := this.txs[txHash]; ok {
+		return errDuplicatedTx
+	}
+	for _, ptx := range this.pool {
+		if ptx.Hash() == txHash {
+			return errDuplicatedTx
+		}
+	}
+
+	inputs := make(map[Uint256]Fixed64)
+	for _, input := range tx.UTXOInputs {
+		k := utxoKey{txId: input.ReferTxID, index: input.ReferTxOutputIndex}
+		output, ok := this.utxos[k]
+		if !ok {
+			return errUnknownInput
+		}
+		if this.poolSpent[k] {
+			return errSpentInput
+		}
+		inputs[output.AssetID] += output.Value
+	}
+	outputs := make(map[Uint256]Fixed64)
+	for _, output := range tx.Outputs {
+		outputs[output.AssetID] += output.Value
+	}
+
+	switch tx.TxType {
+	case transaction.IssueAsset:
+		for assetId, amount := range outputs {
+			regTx, ok := this.txs[assetId]
+			if !ok || regTx.TxType != transaction.RegisterAsset {
+				return errUnknownAsset
+			}
+			regAsset := regTx.Payload.(*payload.RegisterAsset)
+			if this.issued[assetId]+amount > regAsset.Amount {
+				return errAmountExceeded
+			}
+		}
+	case transaction.TransferAsset:
+		if len(inputs) != len(outputs) {
+			return errUnbalanced
+		}
+		for assetId, amount := range outputs {
+			if inputs[assetId] != amount {
+				return errUnbalanced
+			}
+		}
+	case transaction.RegisterAsset, transaction.Record, transaction.IdentityUpdate:
+	default:
+		return fmt.Errorf("unsupported transaction type:%d", tx.TxType)
+	}
+
+	for _, input := range tx.UTXOInputs {
+		this.poolSpent[utxoKey{txId: input.ReferTxID, index: input.ReferTxOutputIndex}] = true
+	}
+	this.pool = append(this.pool, tx)
+	return nil
+}
+
+// generateBlock pack the pool into a new block after a BookKeeping transaction
+func (this *memLedger) generateBlock() *ledger.Block {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	bookKeeping := &transaction.Transaction{
+		TxType:        transaction.BookKeeping,
+		Payload:       &payload.BookKeeping{Nonce: uint64(rand.Int63())},
+		Attributes:    []*transaction.TxAttribute{},
+		UTXOInputs:    []*transaction.UTXOTxInput{},
+		BalanceInputs: []*transaction.BalanceTxInput{},
+		Outputs:       []*transaction.TxOutput{},
+		Programs:      []*program.Program{},
+	}
+	txs := append([]*transaction.Transaction{bookKeeping}, this.pool...)
+	this.pool = nil
+	this.poolSpent = make(map[utxoKey]bool)
+
+	txHashes := make([]Uint256, len(txs))
+	for i, tx := range txs {
+		txHashes[i] = tx.Hash()
+	}
+	txRoot, _ := crypto.ComputeRoot(txHashes)
+
+	timestamp := uint32(time.Now().Unix())
+	if timestamp <= this.lastTime {
+		timestamp = this.lastTime + 1
+	}
+	this.lastTime = timestamp
+	height := uint32(len(this.blocks))
+	prevBlockHash := Uint256{}
+	if height > 0 {
+		prevBlockHash = this.blocks[height-1].Blockdata.Hash()
+	}
+	block := &ledger.Block{
+		Blockdata: &ledger.Blockdata{
+			PrevBlockHash:    prevBlockHash,
+			TransactionsRoot: txRoot,
+			Timestamp:        timestamp,
+			Height:           height,
+			ConsensusData:    uint64(rand.Int63()),
+			Program:          &program.Program{Code: []byte{}, Parameter: []byte{}},
+		},
+		Transactions: txs,
+	}
+	this.blocks = append(this.blocks, block)
+	this.blockIndex[block.Blockdata.Hash()] = height
+	for _, tx := range txs {
+		this.applyTransaction(tx)
+	}
+	return block
+}
+
+func (this *memLedger) applyTransaction(tx *transaction.Transaction) {
+	txHash := tx.Hash()
+	this.txs[txHash] = tx
+	for _, input := range tx.UTXOInputs {
+		delete(this.utxos, utxoKey{txId: input.ReferTxID, index: input.ReferTxOutputIndex})
+	}
+	for i, output := range tx.Outputs {
+		this.utxos[utxoKey{txId: txHash, index: uint16(i)}] = output
+	}
+	switch tx.TxType {
+	case transaction.IssueAsset:
+		for _, output := range tx.Outputs {
+			this.issued[output.AssetID] += output.Value
+		}
+	case transaction.IdentityUpdate:
+		identity := tx.Payload.(*payload.IdentityUpdate)
+		this.identities[string(identity.DID)] = identity.DDO
+	}
+}
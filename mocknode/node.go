@@ -0,0 +1,339 @@
+// Package mocknode is an in-process fake DNA node for integration tests of DnaClient.
+// It serve the JSON-RPC methods used by the SDK from an in-memory ledger.
+package mocknode
+
+import (
+	"DNA/core/ledger"
+	"DNA/core/transaction"
+	. "DNASDK"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// MOCK_NODE_VERSION is returned by getversion
+const MOCK_NODE_VERSION = "mocknode"
+
+type rpcRequest struct {
+	Method string            `json:"method"`
+	Id     interface{}       `json:"id"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JsonRpc string      `json:"jsonrpc"`
+	Id      interface{} `json:"id"`
+	Result  interface{} `json:"result"`
+}
+
+// Node is a fake DNA node listening on a local httptest.Server. Accepted transactions wait
+// in the pool until a block is generated by GenerateBlock, by StartBlockGeneration or by
+// SetAutoGenerate. The genesis block is generated by NewNode.
+type Node struct {
+	server       *httptest.Server
+	ledger       *memLedger
+	lock         sync.Mutex
+	autoGenerate bool
+	stop         chan struct{}
+	closeOnce    sync.Once
+}
+
+// NewNode start a Node, use Close to stop it
+func NewNode() *Node {
+	node := &Node{
+		ledger: newMemLedger(),
+		stop:   make(chan struct{}),
+	}
+	node.server = httptest.NewServer(http.HandlerFunc(node.serveHTTP))
+	return node
+}
+
+// URL return the JSON-RPC address of node, to be used as rpc address of DnaClient
+func (this *Node) URL() string {
+	return this.server.URL
+}
+
+// Close stop the server and the block generation
+func (this *Node) Close() {
+	this.closeOnce.Do(func() {
+		close(this.stop)
+		this.server.Close()
+	})
+}
+
+// Height return the block count of node
+func (this *Node) Height() uint32 {
+	return this.ledger.height()
+}
+
+// GenerateBlock pack the transactions in pool to a new block, and return its height
+func (this *Node) GenerateBlock() uint32 {
+	return this.ledger.generateBlock().Blockdata.Height
+}
+
+// GenerateBlocks generate count blocks, and return the height of the last one
+func (this *Node) GenerateBlocks(count int) uint32 {
+	var height uint32
+	for i := 0; i < count; i++ {
+		height = this.GenerateBlock()
+	}
+	return height
+}
+
+// SetAutoGenerate set whether a block is generated after every accepted transaction
+func (this *Node) SetAutoGenerate(auto bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.autoGenerate = auto
+}
+
+// StartBlockGeneration generate a block every interval until Close
+func (this *Node) StartBlockGeneration(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-this.stop:
+				return
+			case <-ticker.C:
+				this.GenerateBlock()
+			}
+		}
+	}()
+}
+
+func (this *Node) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body = bytes.TrimSpace(body)
+
+	var res interface{}
+	if len(body) > 0 && body[0] == '[' {
+		reqs := make([]*rpcRequest, 0)
+		err = json.Unmarshal(body, &reqs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ress := make([]*rpcResponse, 0, len(reqs))
+		for _, req := range reqs {
+			ress = append(ress, this.handle(req))
+		}
+		res = ress
+	} else {
+		req := &rpcRequest{}
+		err = json.Unmarshal(body, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		res = this.handle(req)
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (this *Node) handle(req *rpcRequest) *rpcResponse {
+	var result interface{}
+	switch req.Method {
+	case DNA_RPC_GETVERSION:
+		result = MOCK_NODE_VERSION
+	case DNA_RPC_GETBLOCKCOUNT:
+		result = this.ledger.height()
+	case DNA_RPC_GETBLOCK:
+		result = this.getBlock(req.Params)
+	case DNA_RPC_GETBLOCKHASH:
+		result = this.getBlockHash(req.Params)
+	case DNA_RPC_GETCURRENTBLOCKHASH:
+		block := this.ledger.getBlock(this.ledger.height() - 1)
+		result = Uint256ToString(block.Blockdata.Hash())
+	case DNA_RPC_GETTRANSACTION:
+		result = this.getTransaction(req.Params)
+	case DNA_RPC_GETUNSPENDOUTPUT:
+		result = this.getUnspendOutput(req.Params)
+	case DNA_RPC_GETIDENTITYUPDATE:
+		result = this.getIdentityUpdate(req.Params)
+	case DNA_RPC_SENDTRANSACTION:
+		result = this.sendTransaction(req.Params)
+	default:
+		result = DnaRpcUnsupported
+	}
+	return &rpcResponse{JsonRpc: "2.0", Id: req.Id, Result: result}
+}
+
+func (this *Node) getBlock(params []json.RawMessage) interface{} {
+	if len(params) < 1 {
+		return DnaRpcInvalidParameter
+	}
+	var height uint32
+	err := json.Unmarshal(params[0], &height)
+	if err == nil {
+		block := this.ledger.getBlock(height)
+		if block == nil {
+			return DnaRpcUnknownBlock
+		}
+		return marshalBlock(block)
+	}
+	var hashStr string
+	err = json.Unmarshal(params[0], &hashStr)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	hash, err := ParseUint256FromString(hashStr)
+	if err != nil {
+		return DnaRpcInvalidHash
+	}
+	block := this.ledger.getBlockByHash(hash)
+	if block == nil {
+		return DnaRpcUnknownBlock
+	}
+	return marshalBlock(block)
+}
+
+func marshalBlock(block *ledger.Block) interface{} {
+	info, err := blockInfo(block)
+	if err != nil {
+		return DnaRpcInternalError
+	}
+	return info
+}
+
+func (this *Node) getBlockHash(params []json.RawMessage) interface{} {
+	if len(params) < 1 {
+		return DnaRpcInvalidParameter
+	}
+	var height uint32
+	err := json.Unmarshal(params[0], &height)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	block := this.ledger.getBlock(height)
+	if block == nil {
+		return DnaRpcUnknownBlock
+	}
+	return Uint256ToString(block.Blockdata.Hash())
+}
+
+func (this *Node) getTransaction(params []json.RawMessage) interface{} {
+	if len(params) < 1 {
+		return DnaRpcInvalidParameter
+	}
+	var hashStr string
+	err := json.Unmarshal(params[0], &hashStr)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	txHash, err := ParseUint256FromString(hashStr)
+	if err != nil {
+		return DnaRpcInvalidHash
+	}
+	tx := this.ledger.getTransaction(txHash)
+	if tx == nil {
+		return DnaRpcUnknownTransaction
+	}
+	txInfo, err := transactionInfo(tx)
+	if err != nil {
+		return DnaRpcInternalError
+	}
+	return txInfo
+}
+
+func (this *Node) getUnspendOutput(params []json.RawMessage) interface{} {
+	if len(params) < 2 {
+		return DnaRpcInvalidParameter
+	}
+	var programHashStr, assetIdStr string
+	err := json.Unmarshal(params[0], &programHashStr)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	err = json.Unmarshal(params[1], &assetIdStr)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	programHash, err := ParseUint160FromString(programHashStr)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	assetId, err := ParseUint256FromString(assetIdStr)
+	if err != nil {
+		return DnaRpcInvalidHash
+	}
+	outputs := make(map[string]TxoutputInfo)
+	for k, output := range this.ledger.getUnspents(programHash, assetId) {
+		outputs[fmt.Sprintf("%s:%d", Uint256ToString(k.txId), k.index)] = outputInfo(output)
+	}
+	return outputs
+}
+
+func (this *Node) getIdentityUpdate(params []json.RawMessage) interface{} {
+	if len(params) < 2 {
+		return DnaRpcInvalidParameter
+	}
+	var method, id string
+	err := json.Unmarshal(params[0], &method)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	err = json.Unmarshal(params[1], &id)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	ddo, ok := this.ledger.getIdentity(fmt.Sprintf("did:%s:%s", method, id))
+	if !ok {
+		return nil
+	}
+	return string(ddo)
+}
+
+func (this *Node) sendTransaction(params []json.RawMessage) interface{} {
+	if len(params) < 1 {
+		return DnaRpcInvalidParameter
+	}
+	var txStr string
+	err := json.Unmarshal(params[0], &txStr)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	txData, err := hex.DecodeString(txStr)
+	if err != nil {
+		return DnaRpcInvalidParameter
+	}
+	tx := &transaction.Transaction{}
+	err = tx.Deserialize(bytes.NewReader(txData))
+	if err != nil {
+		return DnaRpcInvalidTransaction
+	}
+	err = this.ledger.appendTransaction(tx)
+	if err != nil {
+		return DnaRpcInvalidTransaction
+	}
+
+	this.lock.Lock()
+	autoGenerate := this.autoGenerate
+	this.lock.Unlock()
+	if autoGenerate {
+		this.GenerateBlock()
+	}
+	return Uint256ToString(tx.Hash())
+}
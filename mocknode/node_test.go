@@ -0,0 +1,159 @@
+package mocknode_test
+
+import (
+	"DNA/account"
+	. "DNA/common"
+	"DNA/core/asset"
+	"DNA/core/transaction"
+	. "DNASDK"
+	"DNASDK/mocknode"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// sendAndWait send tx signed by signer and wait it is in a block
+func sendAndWait(t *testing.T, client *DnaClient, signer *account.Account, tx *transaction.Transaction) Uint256 {
+	txHash, err := client.SendTransaction(signer, tx)
+	if err != nil {
+		t.Fatalf("SendTransaction TxType:%x error:%s", tx.TxType, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	_, _, err = client.WaitForSentTransaction(ctx, tx, 1)
+	if err != nil {
+		t.Fatalf("WaitForSentTransaction TxType:%x error:%s", tx.TxType, err)
+	}
+	return txHash
+}
+
+// unspentValues return the values of the unspent outputs of programHash, by ReferTxID and index
+func unspentValues(t *testing.T, client *DnaClient, assetId Uint256, programHash Uint160) map[string]Fixed64 {
+	unspents, err := client.GetUnspendOutput(assetId, programHash)
+	if err != nil {
+		t.Fatalf("GetUnspendOutput error:%s", err)
+	}
+	values := make(map[string]Fixed64, len(unspents))
+	for _, unspent := range unspents {
+		if unspent.AssetID != assetId || unspent.ProgramHash != programHash {
+			t.Fatalf("GetUnspendOutput return unspent:%+v of another asset or account", unspent)
+		}
+		values[fmt.Sprintf("%x:%d", unspent.ReferTxID.ToArray(), unspent.ReferTxOutputIndex)] = unspent.Value
+	}
+	return values
+}
+
+func TestNodeTransactionFlow(t *testing.T) {
+	node := mocknode.NewNode()
+	defer node.Close()
+	node.SetAutoGenerate(true)
+
+	client, err := NewDnaClientWithOptions([]string{node.URL()}, WithWalletDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	version, err := client.GetVersion()
+	if err != nil {
+		t.Fatalf("GetVersion error:%s", err)
+	}
+	if version != mocknode.MOCK_NODE_VERSION {
+		t.Fatalf("GetVersion:%s expected:%s", version, mocknode.MOCK_NODE_VERSION)
+	}
+
+	walletClient := client.GetWalletClient("mocknode")
+	issuer, err := walletClient.CreateAccount()
+	if err != nil {
+		t.Fatalf("CreateAccount error:%s", err)
+	}
+	receiver, err := walletClient.CreateAccount()
+	if err != nil {
+		t.Fatalf("CreateAccount error:%s", err)
+	}
+	issuerHash, err := client.GetAccountProgramHash(issuer)
+	if err != nil {
+		t.Fatalf("GetAccountProgramHash error:%s", err)
+	}
+	receiverHash, err := client.GetAccountProgramHash(receiver)
+	if err != nil {
+		t.Fatalf("GetAccountProgramHash error:%s", err)
+	}
+
+	//register
+	regAmount := client.MakeAssetAmount(1000)
+	regTx, err := client.NewAssetRegisterTransaction(
+		client.CreateAsset("TS01", 4, asset.Token, asset.UTXO), regAmount, issuer, issuer)
+	if err != nil {
+		t.Fatalf("NewAssetRegisterTransaction error:%s", err)
+	}
+	assetId := sendAndWait(t, client, issuer, regTx)
+
+	//issue
+	issueAmount := client.MakeAssetAmount(100)
+	issueTx, err := client.NewIssueAssetTransaction([]*transaction.TxOutput{
+		{AssetID: assetId, Value: issueAmount, ProgramHash: issuerHash},
+	})
+	if err != nil {
+		t.Fatalf("NewIssueAssetTransaction error:%s", err)
+	}
+	issueHash := sendAndWait(t, client, issuer, issueTx)
+
+	values := unspentValues(t, client, assetId, issuerHash)
+	issued := fmt.Sprintf("%x:%d", issueHash.ToArray(), 0)
+	if len(values) != 1 || values[issued] != issueAmount {
+		t.Fatalf("unspents after issue:%v expected %s:%v", values, issued, issueAmount)
+	}
+
+	//transfer
+	transferAmount := client.MakeAssetAmount(30)
+	transferTx, err := client.NewTransferAssetTransaction(
+		[]*transaction.UTXOTxInput{{ReferTxID: issueHash, ReferTxOutputIndex: 0}},
+		[]*transaction.TxOutput{
+			{AssetID: assetId, Value: transferAmount, ProgramHash: receiverHash},
+			{AssetID: assetId, Value: issueAmount - transferAmount, ProgramHash: issuerHash},
+		})
+	if err != nil {
+		t.Fatalf("NewTransferAssetTransaction error:%s", err)
+	}
+	transferHash := sendAndWait(t, client, issuer, transferTx)
+
+	values = unspentValues(t, client, assetId, issuerHash)
+	change := fmt.Sprintf("%x:%d", transferHash.ToArray(), 1)
+	if len(values) != 1 || values[change] != issueAmount-transferAmount {
+		t.Fatalf("issuer unspents after transfer:%v expected %s:%v", values, change, issueAmount-transferAmount)
+	}
+	values = unspentValues(t, client, assetId, receiverHash)
+	received := fmt.Sprintf("%x:%d", transferHash.ToArray(), 0)
+	if len(values) != 1 || values[received] != transferAmount {
+		t.Fatalf("receiver unspents after transfer:%v expected %s:%v", values, received, transferAmount)
+	}
+
+	//the spent output cannot be spent again
+	doubleSpendTx, err := client.NewTransferAssetTransaction(
+		[]*transaction.UTXOTxInput{{ReferTxID: issueHash, ReferTxOutputIndex: 0}},
+		[]*transaction.TxOutput{{AssetID: assetId, Value: issueAmount, ProgramHash: receiverHash}})
+	if err != nil {
+		t.Fatalf("NewTransferAssetTransaction error:%s", err)
+	}
+	_, err = client.SendTransaction(issuer, doubleSpendTx)
+	if err == nil {
+		t.Fatalf("SendTransaction double spend should fail")
+	}
+
+	//identity update, stored by DID and got by method and id
+	method, id := "poc", "123456"
+	ddo := []byte("Hello world")
+	identityTx, err := client.NewIdentityUpdateTransaction(issuer.PubKey(), []byte(fmt.Sprintf("did:%s:%s", method, id)), ddo)
+	if err != nil {
+		t.Fatalf("NewIdentityUpdateTransaction error:%s", err)
+	}
+	sendAndWait(t, client, issuer, identityTx)
+
+	got, err := client.GetIdentityUpdate(method, id)
+	if err != nil {
+		t.Fatalf("GetIdentityUpdate error:%s", err)
+	}
+	if string(got) != string(ddo) {
+		t.Fatalf("GetIdentityUpdate:%s expected:%s", got, ddo)
+	}
+}
@@ -0,0 +1,25 @@
+package dnasdk
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// NonceSource generate the Nonce attribute of the transactions created by DnaClient
+type NonceSource func() int64
+
+// NewRandNonceSource return a NonceSource with its own rand seeded by seed, safe for concurrent use
+func NewRandNonceSource(seed int64) NonceSource {
+	lock := &sync.Mutex{}
+	r := rand.New(rand.NewSource(seed))
+	return func() int64 {
+		lock.Lock()
+		defer lock.Unlock()
+		return r.Int63()
+	}
+}
+
+func defaultNonceSource() NonceSource {
+	return NewRandNonceSource(time.Now().UnixNano())
+}
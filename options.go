@@ -0,0 +1,245 @@
+package dnasdk
+
+import (
+	"DNA/crypto"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	CRYPTO_ALG_P256R1 = "P256R1"
+	CRYPTO_ALG_SM2    = "SM2"
+)
+
+var (
+	cryptoAlgLock   sync.Mutex
+	globalCryptoAlg string
+)
+
+// setCryptoAlg set the global algorithm of the DNA crypto package. It fails if another client
+// has set a different algorithm, the keys and signatures of that client would be broken.
+func setCryptoAlg(alg string) error {
+	cryptoAlgLock.Lock()
+	defer cryptoAlgLock.Unlock()
+	if globalCryptoAlg != "" && globalCryptoAlg != alg {
+		return fmt.Errorf("crypto algorithm:%s conflicts with:%s set by another client", alg, globalCryptoAlg)
+	}
+	crypto.SetAlg(alg)
+	globalCryptoAlg = alg
+	return nil
+}
+
+// setDefaultCryptoAlg set P256R1 if no client has set the algorithm. DNA crypto has no curve
+// before SetAlg, the accounts and public keys cannot be used without it.
+func setDefaultCryptoAlg() {
+	cryptoAlgLock.Lock()
+	defer cryptoAlgLock.Unlock()
+	if globalCryptoAlg == "" {
+		crypto.SetAlg(CRYPTO_ALG_P256R1)
+		globalCryptoAlg = CRYPTO_ALG_P256R1
+	}
+}
+
+// ClientOption change a setting of DnaClient in NewDnaClientWithOptions
+type ClientOption func(client *DnaClient) error
+
+func (this *DnaClient) httpTransport() (*http.Transport, error) {
+	transport, ok := this.client.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("http client transport:%T is not *http.Transport", this.client.Transport)
+	}
+	return transport, nil
+}
+
+// WithTimeout set the timeout of a request, include the wait of response header
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(client *DnaClient) error {
+		transport, err := client.httpTransport()
+		if err != nil {
+			return err
+		}
+		transport.ResponseHeaderTimeout = timeout
+		client.client.Timeout = timeout
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost set the max idle connections kept to each node
+func WithMaxIdleConnsPerHost(maxIdleConns int) ClientOption {
+	return func(client *DnaClient) error {
+		transport, err := client.httpTransport()
+		if err != nil {
+			return err
+		}
+		transport.MaxIdleConnsPerHost = maxIdleConns
+		return nil
+	}
+}
+
+// WithIdleConnTimeout set how long an idle connection is kept
+func WithIdleConnTimeout(timeout time.Duration) ClientOption {
+	return func(client *DnaClient) error {
+		transport, err := client.httpTransport()
+		if err != nil {
+			return err
+		}
+		transport.IdleConnTimeout = timeout
+		return nil
+	}
+}
+
+// WithKeepAlive enable or disable the keepalive of connections
+func WithKeepAlive(keepAlive bool) ClientOption {
+	return func(client *DnaClient) error {
+		transport, err := client.httpTransport()
+		if err != nil {
+			return err
+		}
+		transport.DisableKeepAlives = !keepAlive
+		return nil
+	}
+}
+
+// WithHttpClient replace the http client of DnaClient, the transport keeps its kind with the new client.
+// The settings of httpClient win, the settings not in it are kept from the options before, like the
+// timeout, the connections and the TLS of NewDnaClientFromConfig. httpClient is copied, not changed.
+func WithHttpClient(httpClient *http.Client) ClientOption {
+	return func(client *DnaClient) error {
+		merged := *httpClient
+		if merged.Timeout == 0 {
+			merged.Timeout = client.client.Timeout
+		}
+		current, _ := client.client.Transport.(*http.Transport)
+		switch transport := merged.Transport.(type) {
+		case nil:
+			if current != nil {
+				merged.Transport = current.Clone()
+			}
+		case *http.Transport:
+			if transport.TLSClientConfig == nil && current != nil && current.TLSClientConfig != nil {
+				transport = transport.Clone()
+				transport.TLSClientConfig = current.TLSClientConfig
+				merged.Transport = transport
+			}
+		}
+		client.client = &merged
+		if _, ok := client.transport.(*RestTransport); ok {
+			client.transport = NewRestTransport(client.client)
+		} else {
+			client.transport = NewJsonRpcTransport(client.client)
+		}
+		return nil
+	}
+}
+
+// WithTLS set the TLS options of the connections to nodes
+func WithTLS(options *TLSOptions) ClientOption {
+	return func(client *DnaClient) error {
+		return client.SetTLS(options)
+	}
+}
+
+// WithRestTransport send requests to the REST port of nodes. GetUnspendOutput, GetVersion
+// and GetIdentityUpdate are not supported over REST, see RestTransport.
+func WithRestTransport() ClientOption {
+	return func(client *DnaClient) error {
+		client.transport = NewRestTransport(client.client)
+		return nil
+	}
+}
+
+// WithTransport set the transport of DnaClient
+func WithTransport(transport Transport) ClientOption {
+	return func(client *DnaClient) error {
+		client.transport = transport
+		return nil
+	}
+}
+
+// WithEndpointSelector set the policy used to choose among rpcAddresses
+func WithEndpointSelector(selector EndpointSelector) ClientOption {
+	return func(client *DnaClient) error {
+		client.SetEndpointSelector(selector)
+		return nil
+	}
+}
+
+// WithEndpointCooldown set how long a failed endpoint is taken out of rotation
+func WithEndpointCooldown(cooldown time.Duration) ClientOption {
+	return func(client *DnaClient) error {
+		client.SetEndpointCooldown(cooldown)
+		return nil
+	}
+}
+
+// WithRetryPolicy set the retry policy of requests
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(client *DnaClient) error {
+		client.SetRetryPolicy(policy)
+		return nil
+	}
+}
+
+// WithInterceptors add interceptors around the round trips of requests
+func WithInterceptors(interceptors ...RpcInterceptor) ClientOption {
+	return func(client *DnaClient) error {
+		client.AddInterceptor(interceptors...)
+		return nil
+	}
+}
+
+// WithBatchInterceptors add interceptors around the round trips of batch requests
+func WithBatchInterceptors(interceptors ...BatchInterceptor) ClientOption {
+	return func(client *DnaClient) error {
+		client.AddBatchInterceptor(interceptors...)
+		return nil
+	}
+}
+
+// WithWalletDir set the directory of the wallet files opened by GetWalletClient
+func WithWalletDir(walletDir string) ClientOption {
+	return func(client *DnaClient) error {
+		client.walletDir = walletDir
+		return nil
+	}
+}
+
+// WithCryptoAlg set the crypto algorithm, CRYPTO_ALG_P256R1 or CRYPTO_ALG_SM2. The DNA crypto
+// package keeps the algorithm globally, it is set when the client is created, and creating a
+// client with a different algorithm fails. Without this option, P256R1 is set by the first client
+// and the later clients keep the algorithm set before.
+func WithCryptoAlg(alg string) ClientOption {
+	return func(client *DnaClient) error {
+		if alg != CRYPTO_ALG_P256R1 && alg != CRYPTO_ALG_SM2 {
+			return fmt.Errorf("unsupported crypto algorithm:%s", alg)
+		}
+		client.cryptoAlg = alg
+		return nil
+	}
+}
+
+// WithNonceSource set the generator of the Nonce attribute of new transactions
+func WithNonceSource(nonceSource NonceSource) ClientOption {
+	return func(client *DnaClient) error {
+		client.nonceSource = nonceSource
+		return nil
+	}
+}
+
+// WithLogger set the Logger of DnaClient, default is NopLogger
+func WithLogger(logger Logger) ClientOption {
+	return func(client *DnaClient) error {
+		client.logger = logger
+		return nil
+	}
+}
+
+// WithStrictMode set whether the hashes of blocks and transactions are checked, see SetStrictMode
+func WithStrictMode(strict bool) ClientOption {
+	return func(client *DnaClient) error {
+		client.strict = strict
+		return nil
+	}
+}
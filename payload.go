@@ -0,0 +1,257 @@
+package dnasdk
+
+import (
+	"DNA/core/contract"
+	"DNA/core/transaction"
+	txpl "DNA/core/transaction/payload"
+	"DNA/crypto"
+	"DNA/net/httpjsonrpc"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// PayloadParser parse the JSON payload returned by node to the payload of transaction
+type PayloadParser func(data json.RawMessage) (transaction.Payload, error)
+
+// PayloadEncoder encode the payload of transaction to the JSON returned by node
+type PayloadEncoder func(payload transaction.Payload) (json.RawMessage, error)
+
+var (
+	payloadLock    sync.RWMutex
+	payloadParsers = map[transaction.TransactionType]PayloadParser{
+		transaction.BookKeeping:    parseBookKeepingPayload,
+		transaction.IssueAsset:     parseIssueAssetPayload,
+		transaction.BookKeeper:     parseBookKeeperPayload,
+		transaction.RegisterAsset:  parseRegisterAssetPayload,
+		transaction.TransferAsset:  parseTransferAssetPayload,
+		transaction.Record:         parseRecordPayload,
+		transaction.DeployCode:     parseDeployCodePayload,
+		transaction.IdentityUpdate: parseIdentityUpdatePayload,
+	}
+	payloadEncoders = map[transaction.TransactionType]PayloadEncoder{
+		transaction.BookKeeping:    encodeBookKeepingPayload,
+		transaction.IssueAsset:     encodeEmptyPayload,
+		transaction.BookKeeper:     encodeBookKeeperPayload,
+		transaction.RegisterAsset:  encodeRegisterAssetPayload,
+		transaction.TransferAsset:  encodeEmptyPayload,
+		transaction.Record:         encodeRecordPayload,
+		transaction.DeployCode:     encodeDeployCodePayload,
+		transaction.IdentityUpdate: encodeIdentityUpdatePayload,
+	}
+)
+
+// RegisterPayloadParser set the parser of txType used by ParseToPayload, ParseTransaction and
+// ParseBlock. It is used for the custom transaction types of node, or to replace a builtin parser.
+func RegisterPayloadParser(txType transaction.TransactionType, parser PayloadParser) {
+	payloadLock.Lock()
+	defer payloadLock.Unlock()
+	payloadParsers[txType] = parser
+}
+
+// RegisterPayloadEncoder set the encoder of txType used by EncodePayload
+func RegisterPayloadEncoder(txType transaction.TransactionType, encoder PayloadEncoder) {
+	payloadLock.Lock()
+	defer payloadLock.Unlock()
+	payloadEncoders[txType] = encoder
+}
+
+func getPayloadParser(txType transaction.TransactionType) (PayloadParser, bool) {
+	payloadLock.RLock()
+	defer payloadLock.RUnlock()
+	parser, ok := payloadParsers[txType]
+	return parser, ok
+}
+
+// EncodePayload encode payload to the JSON of node with the PayloadEncoder registered for txType
+func EncodePayload(txType transaction.TransactionType, payload transaction.Payload) (json.RawMessage, error) {
+	payloadLock.RLock()
+	encoder, ok := payloadEncoders[txType]
+	payloadLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w TxType:%x", ErrUnknownTxType, txType)
+	}
+	return encoder(payload)
+}
+
+func parseBookKeepingPayload(data json.RawMessage) (transaction.Payload, error) {
+	p := &PayloadBookKeepingInfo{}
+	err := json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal payload BookKeepingInfo:%s error:%w", data, err)
+	}
+	return &txpl.BookKeeping{Nonce: p.Nonce}, nil
+}
+
+func parseIssueAssetPayload(data json.RawMessage) (transaction.Payload, error) {
+	return &txpl.IssueAsset{}, nil
+}
+
+func parseTransferAssetPayload(data json.RawMessage) (transaction.Payload, error) {
+	return &txpl.TransferAsset{}, nil
+}
+
+func parseBookKeeperPayload(data json.RawMessage) (transaction.Payload, error) {
+	p := &PayloadBookKeeperInfo{}
+	err := json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal payload BookKeeperInfo:%s error:%w", data, err)
+	}
+	bookKeeper, err := ParseBookKeeperInfo(p)
+	if err != nil {
+		return nil, fmt.Errorf("ParseBookKeeperInfo error:%w", err)
+	}
+	return bookKeeper, nil
+}
+
+func parseRegisterAssetPayload(data json.RawMessage) (transaction.Payload, error) {
+	p := &PayloadRegisterAssetInfo{}
+	err := json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal payload RegisterAssetInfo:%s error:%w", data, err)
+	}
+	regAsset, err := ParseRegisterAssetInfo(p)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePayloadRegisterAssetInfo error:%w", err)
+	}
+	return regAsset, nil
+}
+
+func parseRecordPayload(data json.RawMessage) (transaction.Payload, error) {
+	p := &PayloadRecord{}
+	err := json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal payload Record:%s error:%w", data, err)
+	}
+	record, err := ParseRecord(p)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePayloadRecord error:%w", err)
+	}
+	return record, nil
+}
+
+func parseDeployCodePayload(data json.RawMessage) (transaction.Payload, error) {
+	p := &PayloadDeployCodeInfo{}
+	err := json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal payload DeployCodeInfo:%s error:%w", data, err)
+	}
+	deplyCode, err := ParseDeployCodeInfo(p)
+	if err != nil {
+		return nil, fmt.Errorf("ParsePayloadDeployCodeInfo error:%w", err)
+	}
+	return deplyCode, nil
+}
+
+func parseIdentityUpdatePayload(data json.RawMessage) (transaction.Payload, error) {
+	p := &PayloadIdentityUpdateInfo{}
+	err := json.Unmarshal(data, p)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal payload IdentityUpdateInfo:%s error:%w", data, err)
+	}
+	identityUpdate, err := ParseIdentityUpdateInfo(p)
+	if err != nil {
+		return nil, fmt.Errorf("ParseIdentityUpdateInfo error:%w", err)
+	}
+	return identityUpdate, nil
+}
+
+func issuerInfo(pubKey *crypto.PubKey) httpjsonrpc.IssuerInfo {
+	if pubKey == nil || pubKey.X == nil || pubKey.Y == nil {
+		return httpjsonrpc.IssuerInfo{}
+	}
+	return httpjsonrpc.IssuerInfo{X: pubKey.X.String(), Y: pubKey.Y.String()}
+}
+
+func payloadTypeError(payload transaction.Payload) error {
+	return fmt.Errorf("payload type:%T error", payload)
+}
+
+func encodeEmptyPayload(payload transaction.Payload) (json.RawMessage, error) {
+	return json.RawMessage("null"), nil
+}
+
+func encodeBookKeepingPayload(payload transaction.Payload) (json.RawMessage, error) {
+	p, ok := payload.(*txpl.BookKeeping)
+	if !ok {
+		return nil, payloadTypeError(payload)
+	}
+	return json.Marshal(&PayloadBookKeepingInfo{Nonce: p.Nonce})
+}
+
+func encodeBookKeeperPayload(payload transaction.Payload) (json.RawMessage, error) {
+	p, ok := payload.(*txpl.BookKeeper)
+	if !ok {
+		return nil, payloadTypeError(payload)
+	}
+	pubKey, err := p.PubKey.EncodePoint(true)
+	if err != nil {
+		return nil, fmt.Errorf("EncodePoint error:%w", err)
+	}
+	action := "add"
+	if p.Action == txpl.BookKeeperAction_SUB {
+		action = "sub"
+	}
+	return json.Marshal(&PayloadBookKeeperInfo{
+		PubKey:     hex.EncodeToString(pubKey),
+		Action:     action,
+		Issuer:     issuerInfo(p.Issuer),
+		Controller: hex.EncodeToString(p.Cert),
+	})
+}
+
+func encodeRegisterAssetPayload(payload transaction.Payload) (json.RawMessage, error) {
+	p, ok := payload.(*txpl.RegisterAsset)
+	if !ok {
+		return nil, payloadTypeError(payload)
+	}
+	return json.Marshal(&PayloadRegisterAssetInfo{
+		Asset:      p.Asset,
+		Amount:     p.Amount,
+		Issuer:     issuerInfo(p.Issuer),
+		Controller: Uint160ToString(p.Controller),
+	})
+}
+
+func encodeRecordPayload(payload transaction.Payload) (json.RawMessage, error) {
+	p, ok := payload.(*txpl.Record)
+	if !ok {
+		return nil, payloadTypeError(payload)
+	}
+	return json.Marshal(&PayloadRecord{
+		RecordType: p.RecordType,
+		RecordData: hex.EncodeToString(p.RecordData),
+	})
+}
+
+func encodeDeployCodePayload(payload transaction.Payload) (json.RawMessage, error) {
+	p, ok := payload.(*txpl.DeployCode)
+	if !ok || p.Code == nil {
+		return nil, payloadTypeError(payload)
+	}
+	return json.Marshal(&PayloadDeployCodeInfo{
+		Code: &httpjsonrpc.FunctionCodeInfo{
+			Code:           hex.EncodeToString(p.Code.Code),
+			ParameterTypes: hex.EncodeToString(contract.ContractParameterTypeToByte(p.Code.ParameterTypes)),
+			ReturnTypes:    hex.EncodeToString(contract.ContractParameterTypeToByte(p.Code.ReturnTypes)),
+		},
+		Name:        p.Name,
+		CodeVersion: p.CodeVersion,
+		Author:      p.Author,
+		Email:       p.Email,
+		Description: p.Description,
+	})
+}
+
+func encodeIdentityUpdatePayload(payload transaction.Payload) (json.RawMessage, error) {
+	p, ok := payload.(*txpl.IdentityUpdate)
+	if !ok {
+		return nil, payloadTypeError(payload)
+	}
+	return json.Marshal(&PayloadIdentityUpdateInfo{
+		DID:     hex.EncodeToString(p.DID),
+		DDO:     hex.EncodeToString(p.DDO),
+		Updater: issuerInfo(p.Updater),
+	})
+}
@@ -0,0 +1,158 @@
+package dnasdk
+
+import (
+	"DNA/account"
+	. "DNA/common"
+	"DNA/core/asset"
+	"DNA/core/code"
+	"DNA/core/contract"
+	"DNA/core/transaction"
+	txpl "DNA/core/transaction/payload"
+	"DNA/crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"testing"
+)
+
+func TestPayloadRoundTrip(t *testing.T) {
+	bookKeeper, err := account.NewAccount()
+	if err != nil {
+		t.Fatalf("NewAccount error:%s", err)
+	}
+	issuer := &crypto.PubKey{X: big.NewInt(1234567), Y: big.NewInt(7654321)}
+	cases := []struct {
+		txType  transaction.TransactionType
+		payload transaction.Payload
+	}{
+		{txType: transaction.BookKeeping, payload: &txpl.BookKeeping{Nonce: 20170101}},
+		{txType: transaction.IssueAsset, payload: &txpl.IssueAsset{}},
+		{txType: transaction.TransferAsset, payload: &txpl.TransferAsset{}},
+		{txType: transaction.BookKeeper, payload: &txpl.BookKeeper{
+			PubKey: bookKeeper.PubKey(),
+			Action: txpl.BookKeeperAction_SUB,
+			Cert:   []byte("cert"),
+			Issuer: issuer,
+		}},
+		{txType: transaction.RegisterAsset, payload: &txpl.RegisterAsset{
+			Asset:      &asset.Asset{Name: "TS01", Description: "test", Precision: 4, AssetType: asset.Token, RecordType: asset.UTXO},
+			Amount:     Fixed64(100000),
+			Issuer:     issuer,
+			Controller: Uint160{1, 2, 3},
+		}},
+		{txType: transaction.Record, payload: &txpl.Record{RecordType: "test", RecordData: []byte("Hello world")}},
+		{txType: transaction.DeployCode, payload: &txpl.DeployCode{
+			Code: &code.FunctionCode{
+				Code:           []byte{0x51, 0xAC},
+				ParameterTypes: contract.ByteToContractParameterType([]byte{0, 1}),
+				ReturnTypes:    contract.ByteToContractParameterType([]byte{1}),
+			},
+			Name:        "test",
+			CodeVersion: "1.0",
+			Author:      "author",
+			Email:       "author@dna.test",
+			Description: "test code",
+		}},
+		{txType: transaction.IdentityUpdate, payload: &txpl.IdentityUpdate{
+			DID:     []byte("did:poc:123456"),
+			DDO:     []byte("Hello world"),
+			Updater: issuer,
+		}},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%T", c.payload), func(t *testing.T) {
+			data, err := EncodePayload(c.txType, c.payload)
+			if err != nil {
+				t.Fatalf("EncodePayload error:%s", err)
+			}
+			parsed, err := ParseToPayload(c.txType, data)
+			if err != nil {
+				t.Fatalf("parse payload:%s error:%s", data, err)
+			}
+			if fmt.Sprintf("%T", parsed) != fmt.Sprintf("%T", c.payload) {
+				t.Fatalf("parsed payload type:%T expected:%T", parsed, c.payload)
+			}
+			//the parsed payload is encoded as the original
+			encoded, err := EncodePayload(c.txType, parsed)
+			if err != nil {
+				t.Fatalf("EncodePayload parsed error:%s", err)
+			}
+			if string(encoded) != string(data) {
+				t.Fatalf("parsed payload encoded:%s expected:%s", encoded, data)
+			}
+		})
+	}
+}
+
+func TestPayloadTypeMismatch(t *testing.T) {
+	_, err := EncodePayload(transaction.Record, &txpl.BookKeeping{})
+	if err == nil {
+		t.Fatalf("EncodePayload of another payload type should fail")
+	}
+	_, err = EncodePayload(transaction.TransactionType(0x7e), &txpl.BookKeeping{})
+	if !errors.Is(err, ErrUnknownTxType) {
+		t.Fatalf("EncodePayload of unknown TxType error:%v expected ErrUnknownTxType", err)
+	}
+	_, err = ParseToPayload(transaction.TransactionType(0x7e), json.RawMessage("{}"))
+	if !errors.Is(err, ErrUnknownTxType) {
+		t.Fatalf("ParseToPayload of unknown TxType error:%v expected ErrUnknownTxType", err)
+	}
+}
+
+// testPayload is the payload of a custom transaction type of node
+type testPayload struct {
+	Message string
+}
+
+func (this *testPayload) Data(version byte) []byte {
+	return []byte(this.Message)
+}
+
+func (this *testPayload) Serialize(w io.Writer, version byte) error {
+	_, err := w.Write([]byte(this.Message))
+	return err
+}
+
+func (this *testPayload) Deserialize(r io.Reader, version byte) error {
+	return fmt.Errorf("not supported")
+}
+
+func TestRegisterPayload(t *testing.T) {
+	txType := transaction.TransactionType(0x7f)
+	t.Cleanup(func() {
+		payloadLock.Lock()
+		defer payloadLock.Unlock()
+		delete(payloadParsers, txType)
+		delete(payloadEncoders, txType)
+	})
+	RegisterPayloadParser(txType, func(data json.RawMessage) (transaction.Payload, error) {
+		p := &testPayload{}
+		err := json.Unmarshal(data, p)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	})
+	RegisterPayloadEncoder(txType, func(payload transaction.Payload) (json.RawMessage, error) {
+		p, ok := payload.(*testPayload)
+		if !ok {
+			return nil, payloadTypeError(payload)
+		}
+		return json.Marshal(p)
+	})
+
+	data, err := EncodePayload(txType, &testPayload{Message: "Hello world"})
+	if err != nil {
+		t.Fatalf("EncodePayload error:%s", err)
+	}
+	parsed, err := ParseToPayload(txType, data)
+	if err != nil {
+		t.Fatalf("parse payload:%s error:%s", data, err)
+	}
+	p, ok := parsed.(*testPayload)
+	if !ok || p.Message != "Hello world" {
+		t.Fatalf("parsed payload:%+v expected Hello world", parsed)
+	}
+}
@@ -0,0 +1,220 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"DNA/core/transaction"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Fixture is a recorded round trip. The fixtures of the same method and params are saved in
+// order in one file of the fixture directory.
+//
+// The fixtures of sendrawtransaction are saved by the hash of the transaction instead of params,
+// the signature of a resigned transaction is different but its hash is not. The Nonce attribute
+// is in the hash, so the client recording and the client replaying need the same fixed NonceSource
+// (see WithNonceSource) to build the same transactions.
+type Fixture struct {
+	Method    string
+	Params    json.RawMessage
+	Raw       string
+	Result    string
+	Error     string `json:",omitempty"`
+	ErrorCode int64  `json:",omitempty"`
+}
+
+func fixtureFile(dir, method string, params []interface{}) (string, json.RawMessage, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", nil, fmt.Errorf("json.Marshal params error:%w", err)
+	}
+	if method == DNA_RPC_SENDTRANSACTION {
+		txHash, ok := fixtureTxHash(params)
+		if ok {
+			return filepath.Join(dir, fmt.Sprintf("%s_%x.json", method, txHash.ToArray())), data, nil
+		}
+	}
+	sum := sha256.Sum256(data)
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.json", method, hex.EncodeToString(sum[:8]))), data, nil
+}
+
+// fixtureTxHash return the hash of the signed transaction in the params of sendrawtransaction
+func fixtureTxHash(params []interface{}) (Uint256, bool) {
+	if len(params) != 1 {
+		return Uint256{}, false
+	}
+	txStr, ok := params[0].(string)
+	if !ok {
+		return Uint256{}, false
+	}
+	txData, err := hex.DecodeString(txStr)
+	if err != nil {
+		return Uint256{}, false
+	}
+	tx := &transaction.Transaction{}
+	err = tx.Deserialize(bytes.NewReader(txData))
+	if err != nil {
+		return Uint256{}, false
+	}
+	return ComputeTransactionHash(tx), true
+}
+
+// RecordingTransport send requests with the next Transport, and save every response
+// as a Fixture in dir, to be served by ReplayTransport. The fixtures already in dir, like
+// the ones of a previous session, are kept and the new ones are appended after them.
+type RecordingTransport struct {
+	next     Transport
+	dir      string
+	lock     sync.Mutex
+	recorded map[string][]*Fixture
+}
+
+func NewRecordingTransport(next Transport, dir string) *RecordingTransport {
+	return &RecordingTransport{
+		next:     next,
+		dir:      dir,
+		recorded: make(map[string][]*Fixture),
+	}
+}
+
+func (this *RecordingTransport) RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	res, err := this.next.RoundTrip(ctx, address, req)
+	if err != nil {
+		return nil, err
+	}
+	file, params, err := fixtureFile(this.dir, req.Method, req.Params)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &Fixture{
+		Method: req.Method,
+		Params: params,
+		Raw:    string(res.Raw),
+		Result: string(res.Result),
+	}
+	rpcErr := &RpcError{}
+	if errors.As(res.Error, &rpcErr) {
+		fixture.Error = rpcErr.Message
+		fixture.ErrorCode = rpcErr.Code
+	} else if res.Error != nil {
+		fixture.Error = res.Error.Error()
+	}
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	fixtures, ok := this.recorded[file]
+	if !ok {
+		fixtures, err = readFixtures(file)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	fixtures = append(fixtures, fixture)
+	data, err := json.MarshalIndent(fixtures, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal fixtures error:%w", err)
+	}
+	err = os.MkdirAll(this.dir, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("MkdirAll:%s error:%w", this.dir, err)
+	}
+	err = ioutil.WriteFile(file, data, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("WriteFile:%s error:%w", file, err)
+	}
+	this.recorded[file] = fixtures
+	return res, nil
+}
+
+// ErrFixtureNotFound is returned by ReplayTransport for a request never recorded
+var ErrFixtureNotFound = errors.New("fixture not found")
+
+// ReplayTransport serve the fixtures saved by RecordingTransport in dir, without any node.
+// The fixtures of a request are served in the recorded order, the last one is repeated after.
+type ReplayTransport struct {
+	dir      string
+	lock     sync.Mutex
+	fixtures map[string][]*Fixture
+	served   map[string]int
+}
+
+func NewReplayTransport(dir string) *ReplayTransport {
+	return &ReplayTransport{
+		dir:      dir,
+		fixtures: make(map[string][]*Fixture),
+		served:   make(map[string]int),
+	}
+}
+
+func (this *ReplayTransport) RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	file, params, err := fixtureFile(this.dir, req.Method, req.Params)
+	if err != nil {
+		return nil, err
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	fixtures, ok := this.fixtures[file]
+	if !ok {
+		fixtures, err = readFixtures(file)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w method:%s params:%s", ErrFixtureNotFound, req.Method, params)
+		}
+		if err != nil {
+			return nil, err
+		}
+		this.fixtures[file] = fixtures
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("%w method:%s params:%s", ErrFixtureNotFound, req.Method, params)
+	}
+	index := this.served[file]
+	if index >= len(fixtures) {
+		index = len(fixtures) - 1
+	}
+	this.served[file] = index + 1
+	fixture := fixtures[index]
+
+	res := &RpcResponse{
+		Raw:    []byte(fixture.Raw),
+		Result: []byte(fixture.Result),
+	}
+	if fixture.Error != "" {
+		res.Result = nil
+		res.Error = fixtureError(fixture)
+	}
+	return res, nil
+}
+
+// readFixtures read the fixtures saved in file, the error of a missing file satisfies os.IsNotExist
+func readFixtures(file string) ([]*Fixture, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ReadFile:%s error:%w", file, err)
+	}
+	fixtures := make([]*Fixture, 0)
+	err = json.Unmarshal(data, &fixtures)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal fixtures:%s error:%w", file, err)
+	}
+	return fixtures, nil
+}
+
+func fixtureError(fixture *Fixture) error {
+	err, ok := dnaRpcErrors[fixture.Error]
+	if ok && fixture.ErrorCode == 0 {
+		return err
+	}
+	return &RpcError{Code: fixture.ErrorCode, Message: fixture.Error}
+}
@@ -0,0 +1,229 @@
+package dnasdk_test
+
+import (
+	"DNA/account"
+	. "DNA/common"
+	"DNA/core/asset"
+	"DNA/core/transaction"
+	. "DNASDK"
+	"DNASDK/mocknode"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fixedNonce is the NonceSource of the recording and the replaying clients, so that
+// they build the same transactions
+func fixedNonce() int64 {
+	return 20170101
+}
+
+// issueAsset register an asset and issue amount of it to owner, return the asset id
+func issueAsset(t *testing.T, client *DnaClient, owner *account.Account, amount Fixed64) Uint256 {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	regTx, err := client.NewAssetRegisterTransaction(
+		client.CreateAsset("TS01", 4, asset.Token, asset.UTXO), amount, owner, owner)
+	if err != nil {
+		t.Fatalf("NewAssetRegisterTransaction error:%s", err)
+	}
+	assetId, err := client.SendTransaction(owner, regTx)
+	if err != nil {
+		t.Fatalf("SendTransaction register error:%s", err)
+	}
+	_, _, err = client.WaitForSentTransaction(ctx, regTx, 1)
+	if err != nil {
+		t.Fatalf("WaitForSentTransaction register error:%s", err)
+	}
+
+	programHash, err := client.GetAccountProgramHash(owner)
+	if err != nil {
+		t.Fatalf("GetAccountProgramHash error:%s", err)
+	}
+	issueTx, err := client.NewIssueAssetTransaction([]*transaction.TxOutput{
+		{AssetID: assetId, Value: amount, ProgramHash: programHash},
+	})
+	if err != nil {
+		t.Fatalf("NewIssueAssetTransaction error:%s", err)
+	}
+	_, err = client.SendTransaction(owner, issueTx)
+	if err != nil {
+		t.Fatalf("SendTransaction issue error:%s", err)
+	}
+	_, _, err = client.WaitForSentTransaction(ctx, issueTx, 1)
+	if err != nil {
+		t.Fatalf("WaitForSentTransaction issue error:%s", err)
+	}
+	return assetId
+}
+
+// newTransfer build the transaction spending all unspents, amount to to and the change back to from
+func newTransfer(t *testing.T, client *DnaClient, unspents []*UnspendUTXO, from, to Uint160, amount Fixed64) *transaction.Transaction {
+	inputs := make([]*transaction.UTXOTxInput, 0, len(unspents))
+	total := Fixed64(0)
+	for _, unspent := range unspents {
+		inputs = append(inputs, &transaction.UTXOTxInput{
+			ReferTxID:          unspent.ReferTxID,
+			ReferTxOutputIndex: unspent.ReferTxOutputIndex,
+		})
+		total += unspent.Value
+	}
+	if total < amount {
+		t.Fatalf("unspent value:%v less than amount:%v", total, amount)
+	}
+	assetId := unspents[0].AssetID
+	outputs := []*transaction.TxOutput{{AssetID: assetId, Value: amount, ProgramHash: to}}
+	if total > amount {
+		outputs = append(outputs, &transaction.TxOutput{AssetID: assetId, Value: total - amount, ProgramHash: from})
+	}
+	tx, err := client.NewTransferAssetTransaction(inputs, outputs)
+	if err != nil {
+		t.Fatalf("NewTransferAssetTransaction error:%s", err)
+	}
+	return tx
+}
+
+// unspentKey is the output referred by an unspent
+type unspentKey struct {
+	txId  Uint256
+	index uint16
+}
+
+func unspentSet(unspents []*UnspendUTXO) map[unspentKey]*UnspendUTXO {
+	set := make(map[unspentKey]*UnspendUTXO, len(unspents))
+	for _, unspent := range unspents {
+		set[unspentKey{txId: unspent.ReferTxID, index: unspent.ReferTxOutputIndex}] = unspent
+	}
+	return set
+}
+
+func TestReplayTransport(t *testing.T) {
+	dir := t.TempDir()
+	fixtureDir := t.TempDir()
+	node := mocknode.NewNode()
+	defer node.Close()
+	node.SetAutoGenerate(true)
+
+	client, err := NewDnaClientWithOptions([]string{node.URL()}, WithWalletDir(dir))
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	walletClient := client.GetWalletClient("replay")
+	from, err := walletClient.CreateAccount()
+	if err != nil {
+		t.Fatalf("CreateAccount error:%s", err)
+	}
+	to, err := walletClient.CreateAccount()
+	if err != nil {
+		t.Fatalf("CreateAccount error:%s", err)
+	}
+	amount := client.MakeAssetAmount(100)
+	assetId := issueAsset(t, client, from, amount)
+	fromHash, _ := client.GetAccountProgramHash(from)
+	toHash, _ := client.GetAccountProgramHash(to)
+	height := node.Height()
+
+	//record the calls against the node
+	recorder, err := NewDnaClientWithOptions([]string{node.URL()},
+		WithTransport(NewRecordingTransport(NewJsonRpcTransport(http.DefaultClient), fixtureDir)),
+		WithNonceSource(fixedNonce),
+	)
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions recorder error:%s", err)
+	}
+	block, err := recorder.GetBlockByHeight(height)
+	if err != nil {
+		t.Fatalf("GetBlockByHeight error:%s", err)
+	}
+	unspents, err := recorder.GetUnspendOutput(assetId, fromHash)
+	if err != nil {
+		t.Fatalf("GetUnspendOutput error:%s", err)
+	}
+	txHash, err := recorder.SendTransaction(from, newTransfer(t, recorder, unspents, fromHash, toHash, client.MakeAssetAmount(10)))
+	if err != nil {
+		t.Fatalf("SendTransaction error:%s", err)
+	}
+	node.Close()
+
+	//replay them without node, the transfer is signed again with another signature
+	replayer, err := NewDnaClientWithOptions([]string{node.URL()},
+		WithTransport(NewReplayTransport(fixtureDir)),
+		WithNonceSource(fixedNonce),
+	)
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions replayer error:%s", err)
+	}
+	replayBlock, err := replayer.GetBlockByHeight(height)
+	if err != nil {
+		t.Fatalf("replay GetBlockByHeight error:%s", err)
+	}
+	if replayBlock.Hash() != block.Hash() {
+		t.Fatalf("replay block hash:%x expected:%x", replayBlock.Hash().ToArray(), block.Hash().ToArray())
+	}
+	replayUnspents, err := replayer.GetUnspendOutput(assetId, fromHash)
+	if err != nil {
+		t.Fatalf("replay GetUnspendOutput error:%s", err)
+	}
+	//node does not keep the order of unspents, compare them by TxId and Index
+	expected, replayed := unspentSet(unspents), unspentSet(replayUnspents)
+	if len(replayUnspents) != len(unspents) || len(replayed) != len(expected) {
+		t.Fatalf("replay unspents:%d expected:%d", len(replayUnspents), len(unspents))
+	}
+	for key, unspent := range replayed {
+		if expected[key] == nil || *expected[key] != *unspent {
+			t.Fatalf("replay unspent:%+v expected:%+v", unspent, expected[key])
+		}
+	}
+	replayHash, err := replayer.SendTransaction(from, newTransfer(t, replayer, replayUnspents, fromHash, toHash, client.MakeAssetAmount(10)))
+	if err != nil {
+		t.Fatalf("replay SendTransaction error:%s", err)
+	}
+	if replayHash != txHash {
+		t.Fatalf("replay tx hash:%x expected:%x", replayHash.ToArray(), txHash.ToArray())
+	}
+
+	_, err = replayer.GetBlockByHeight(height + 100)
+	if err == nil {
+		t.Fatalf("replay GetBlockByHeight not recorded should fail")
+	}
+}
+
+// countTransport answer getblockcount with the count of requests received
+type countTransport struct {
+	count int
+}
+
+func (this *countTransport) RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	this.count++
+	result := []byte(fmt.Sprintf("%d", this.count))
+	return &RpcResponse{Raw: result, Result: result}, nil
+}
+
+func TestRecordingTransportAppend(t *testing.T) {
+	dir := t.TempDir()
+	req := &RpcRequest{Method: DNA_RPC_GETBLOCKCOUNT, Params: []interface{}{}}
+	//two sessions recording the same request
+	for session := 0; session < 2; session++ {
+		recorder := NewRecordingTransport(&countTransport{count: session * 2}, dir)
+		for i := 0; i < 2; i++ {
+			_, err := recorder.RoundTrip(context.Background(), "", req)
+			if err != nil {
+				t.Fatalf("RoundTrip session:%d error:%s", session, err)
+			}
+		}
+	}
+
+	replayer := NewReplayTransport(dir)
+	for expected := 1; expected <= 4; expected++ {
+		res, err := replayer.RoundTrip(context.Background(), "", req)
+		if err != nil {
+			t.Fatalf("replay RoundTrip error:%s", err)
+		}
+		if string(res.Result) != fmt.Sprintf("%d", expected) {
+			t.Fatalf("replay result:%s expected:%d", res.Result, expected)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package dnasdk
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy control how many times a request is tried when the node cannot be reached.
+// Each attempt goes to the next endpoint chosen by the EndpointSelector, and waits
+// an exponential backoff with jitter before it.
+//
+// Read requests are retried on every transport failure. sendrawtransaction is only
+// retried with the same signed bytes, so the resent transaction has the same hash
+// and cannot be accepted twice by the node.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter is the fraction of the backoff which is randomized, in [0, 1]
+	Jitter float64
+	// RetrySendTransaction set to false to never resend sendrawtransaction
+	RetrySendTransaction bool
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       time.Millisecond * 200,
+		MaxBackoff:           time.Second * 5,
+		Multiplier:           2,
+		Jitter:               0.2,
+		RetrySendTransaction: true,
+	}
+}
+
+// Backoff return the time to wait before the attempt, attempt start from 1 for the first retry
+func (this *RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt <= 0 {
+		return 0
+	}
+	backoff := float64(this.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= this.Multiplier
+		if this.MaxBackoff > 0 && backoff >= float64(this.MaxBackoff) {
+			backoff = float64(this.MaxBackoff)
+			break
+		}
+	}
+	if this.Jitter > 0 {
+		backoff += backoff * this.Jitter * (rand.Float64()*2 - 1)
+	}
+	if backoff < 0 {
+		return 0
+	}
+	return time.Duration(backoff)
+}
+
+func (this *RetryPolicy) maxAttempts(method string) int {
+	if method == DNA_RPC_SENDTRANSACTION && !this.RetrySendTransaction {
+		return 1
+	}
+	if this.MaxAttempts <= 0 {
+		return 1
+	}
+	return this.MaxAttempts
+}
+
+func (this *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	backoff := this.Backoff(attempt)
+	if backoff <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,121 @@
+package dnasdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: time.Millisecond * 100, MaxBackoff: time.Second, Multiplier: 2}
+	cases := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{attempt: -1, backoff: 0},
+		{attempt: 0, backoff: 0},
+		{attempt: 1, backoff: time.Millisecond * 100},
+		{attempt: 2, backoff: time.Millisecond * 200},
+		{attempt: 3, backoff: time.Millisecond * 400},
+		{attempt: 4, backoff: time.Millisecond * 800},
+		{attempt: 5, backoff: time.Second},
+		{attempt: 100, backoff: time.Second},
+	}
+	for _, c := range cases {
+		backoff := policy.Backoff(c.attempt)
+		if backoff != c.backoff {
+			t.Fatalf("Backoff attempt:%d backoff:%s expected:%s", c.attempt, backoff, c.backoff)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(2)
+		if backoff < time.Millisecond*100 || backoff > time.Millisecond*300 {
+			t.Fatalf("Backoff with jitter:%s expected in [100ms, 300ms]", backoff)
+		}
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   *RetryPolicy
+		method   string
+		attempts int
+	}{
+		{name: "default read", policy: DefaultRetryPolicy(), method: DNA_RPC_GETBLOCKCOUNT, attempts: 3},
+		{name: "default send", policy: DefaultRetryPolicy(), method: DNA_RPC_SENDTRANSACTION, attempts: 3},
+		{name: "no send retry", policy: &RetryPolicy{MaxAttempts: 3}, method: DNA_RPC_SENDTRANSACTION, attempts: 1},
+		{name: "no send retry read", policy: &RetryPolicy{MaxAttempts: 3}, method: DNA_RPC_GETBLOCKCOUNT, attempts: 3},
+		{name: "zero", policy: &RetryPolicy{}, method: DNA_RPC_GETBLOCKCOUNT, attempts: 1},
+	}
+	for _, c := range cases {
+		attempts := c.policy.maxAttempts(c.method)
+		if attempts != c.attempts {
+			t.Fatalf("%s maxAttempts:%d expected:%d", c.name, attempts, c.attempts)
+		}
+	}
+}
+
+// newFailingClient return a client of a node failing the first failures requests, and the count of
+// requests received by the node
+func newFailingClient(t *testing.T, failures int, policy *RetryPolicy) (*DnaClient, *int) {
+	calls := 0
+	client, err := NewDnaClientWithOptions([]string{"http://node"},
+		WithTransport(funcTransport(func(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+			calls++
+			if calls <= failures {
+				return nil, errors.New("connection refused")
+			}
+			return resultResponse("7"), nil
+		})),
+		WithRetryPolicy(policy),
+	)
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	return client, &calls
+}
+
+func TestRetryTransportFailure(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, Multiplier: 2}
+	client, calls := newFailingClient(t, 2, policy)
+	count, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount error:%s", err)
+	}
+	if count != 7 || *calls != 3 {
+		t.Fatalf("GetBlockCount:%d calls:%d expected:7 calls:3", count, *calls)
+	}
+
+	client, calls = newFailingClient(t, 3, policy)
+	_, err = client.GetBlockCount()
+	if !errors.Is(err, ErrTransport) {
+		t.Fatalf("GetBlockCount error:%v expected ErrTransport", err)
+	}
+	if *calls != 3 {
+		t.Fatalf("GetBlockCount calls:%d expected:3", *calls)
+	}
+
+	//sendrawtransaction is not resent without RetrySendTransaction
+	client, calls = newFailingClient(t, 1, policy)
+	_, err = client.sendRpcRequest(context.Background(), DNA_RPC_SENDTRANSACTION, []interface{}{"00"})
+	if !errors.Is(err, ErrTransport) || *calls != 1 {
+		t.Fatalf("sendrawtransaction error:%v calls:%d expected ErrTransport calls:1", err, *calls)
+	}
+}
+
+func TestRetryBackoffCancel(t *testing.T) {
+	client, calls := newFailingClient(t, 1, &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err := client.GetBlockCountContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetBlockCount error:%v expected DeadlineExceeded", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("GetBlockCount calls:%d expected:1", *calls)
+	}
+}
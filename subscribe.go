@@ -0,0 +1,212 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"DNA/core/ledger"
+	"context"
+	"errors"
+	"time"
+)
+
+type BlockEventType byte
+
+const (
+	BLOCK_EVENT_NEW      BlockEventType = 0
+	BLOCK_EVENT_ROLLBACK BlockEventType = 1
+	BLOCK_EVENT_ERROR    BlockEventType = 2
+)
+
+// SUBSCRIBE_ROLLBACK_DEPTH is the count of delivered blocks kept to roll back
+const SUBSCRIBE_ROLLBACK_DEPTH = 100
+
+// SUBSCRIBE_ERROR_INTERVAL is the min interval between the BLOCK_EVENT_ERROR events of the same poll error
+const SUBSCRIBE_ERROR_INTERVAL = time.Minute
+
+// ErrRollbackTooDeep is sent in a BLOCK_EVENT_ERROR event, when the replaced chain is
+// longer than SUBSCRIBE_ROLLBACK_DEPTH. The subscription continue on the new chain.
+var ErrRollbackTooDeep = errors.New("rollback deeper than the kept blocks")
+
+// BlockEvent is sent by SubscribeBlocks. For BLOCK_EVENT_NEW Block is the new block,
+// for BLOCK_EVENT_ROLLBACK Height and Hash is the delivered block which is no longer in chain.
+// For BLOCK_EVENT_ERROR Err is ErrRollbackTooDeep, or the error of polling the block at Height,
+// the subscription keep polling after it.
+type BlockEvent struct {
+	Type   BlockEventType
+	Height uint32
+	Hash   Uint256
+	Block  *ledger.Block
+	Err    error
+}
+
+type deliveredBlock struct {
+	height uint32
+	hash   Uint256
+	//the block before start is kept to check the first block, but not sent
+	seed bool
+}
+
+// blockSubscriber poll the node for new blocks, and check every block links to
+// the previous one delivered.
+type blockSubscriber struct {
+	client    *DnaClient
+	next      uint32
+	delivered []deliveredBlock
+	events    chan *BlockEvent
+	lastErr   string
+	lastErrAt time.Time
+}
+
+// SubscribeBlocks send the blocks from height start, and every new block after, polling the
+// node every interval. Poll failures are sent as BLOCK_EVENT_ERROR events, the same error at most
+// once every SUBSCRIBE_ERROR_INTERVAL. When the delivered blocks are replaced by another chain, a
+// BLOCK_EVENT_ROLLBACK event is sent for each of them from the top, before the blocks
+// of the new chain. A block count below the delivered blocks, as answered by a lagging endpoint,
+// is waited for and not taken as a rollback. The channel is closed when ctx is done.
+func (this *DnaClient) SubscribeBlocks(ctx context.Context, start uint32, interval time.Duration) <-chan *BlockEvent {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	subscriber := &blockSubscriber{
+		client: this,
+		next:   start,
+		events: make(chan *BlockEvent),
+	}
+	go subscriber.run(ctx, interval)
+	return subscriber.events
+}
+
+func (this *blockSubscriber) run(ctx context.Context, interval time.Duration) {
+	defer close(this.events)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := this.poll(ctx)
+		if ctx.Err() == nil && !this.pollFailed(ctx, err) {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (this *blockSubscriber) poll(ctx context.Context) error {
+	if len(this.delivered) == 0 && this.next > 0 {
+		header, err := this.client.GetBlockHeaderByHeightContext(ctx, this.next-1)
+		if err != nil {
+			return err
+		}
+		this.delivered = append(this.delivered, deliveredBlock{height: header.Height, hash: header.Hash(), seed: true})
+	}
+
+	count, err := this.client.GetBlockCountContext(ctx)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	if count-1 < this.next && len(this.delivered) > 0 {
+		//no new block, check the top block is not replaced
+		last := this.delivered[len(this.delivered)-1]
+		if last.height > count-1 {
+			//the requests may be answered by another endpoint, which is behind the delivered blocks
+			return nil
+		}
+		hash, err := this.client.GetBlockHashContext(ctx, last.height)
+		if errors.Is(err, ErrUnknownBlock) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hash == last.hash {
+			return nil
+		}
+		//the blocks below are checked by the PrevBlockHash of the blocks got next
+		if !this.rollback(ctx) {
+			return ctx.Err()
+		}
+		if len(this.delivered) == 0 {
+			return nil
+		}
+	}
+
+	for this.next < count {
+		block, err := this.client.GetBlockByHeightContext(ctx, this.next)
+		if errors.Is(err, ErrUnknownBlock) {
+			//answered by an endpoint behind the block count, get it at next poll
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(this.delivered) > 0 && block.Blockdata.PrevBlockHash != this.delivered[len(this.delivered)-1].hash {
+			if !this.rollback(ctx) {
+				return ctx.Err()
+			}
+			if len(this.delivered) == 0 {
+				//get the link of the new chain at next poll
+				return nil
+			}
+			continue
+		}
+		hash := block.Blockdata.Hash()
+		if !this.send(ctx, &BlockEvent{Type: BLOCK_EVENT_NEW, Height: this.next, Hash: hash, Block: block}) {
+			return ctx.Err()
+		}
+		this.delivered = append(this.delivered, deliveredBlock{height: this.next, hash: hash})
+		if len(this.delivered) > SUBSCRIBE_ROLLBACK_DEPTH {
+			this.delivered = this.delivered[1:]
+		}
+		this.next++
+	}
+	return nil
+}
+
+// pollFailed send err as a BLOCK_EVENT_ERROR event, unless it is the same error sent less than
+// SUBSCRIBE_ERROR_INTERVAL ago. A nil err reset the last error. Return false when ctx is done.
+func (this *blockSubscriber) pollFailed(ctx context.Context, err error) bool {
+	if err == nil {
+		this.lastErr = ""
+		return true
+	}
+	this.client.logger.Warn("subscribe blocks poll failed", ErrorField(err))
+	now := time.Now()
+	if err.Error() == this.lastErr && now.Sub(this.lastErrAt) < SUBSCRIBE_ERROR_INTERVAL {
+		return true
+	}
+	this.lastErr = err.Error()
+	this.lastErrAt = now
+	return this.send(ctx, &BlockEvent{Type: BLOCK_EVENT_ERROR, Height: this.next, Err: err})
+}
+
+// rollback drop the top delivered block and send its BLOCK_EVENT_ROLLBACK event,
+// return false when ctx is done
+func (this *blockSubscriber) rollback(ctx context.Context) bool {
+	last := this.delivered[len(this.delivered)-1]
+	this.delivered = this.delivered[:len(this.delivered)-1]
+	if last.seed {
+		return true
+	}
+	this.next = last.height
+	if !this.send(ctx, &BlockEvent{Type: BLOCK_EVENT_ROLLBACK, Height: last.height, Hash: last.hash}) {
+		return false
+	}
+	if len(this.delivered) == 0 && last.height > 0 {
+		//the fork point is unknown, continue on the new chain from here
+		return this.send(ctx, &BlockEvent{Type: BLOCK_EVENT_ERROR, Height: last.height, Err: ErrRollbackTooDeep})
+	}
+	return true
+}
+
+func (this *blockSubscriber) send(ctx context.Context, event *BlockEvent) bool {
+	select {
+	case this.events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
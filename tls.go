@@ -0,0 +1,67 @@
+package dnasdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSOptions is the TLS setting to connect to a node with IsTLS enabled.
+// CAPath is the CA to verify the node certificate, system roots are used if empty.
+// CertPath and KeyPath are the client certificate for mutual TLS.
+// ServerName is the name verified against the node certificate, default is the host of rpc address.
+type TLSOptions struct {
+	CAPath             string
+	CertPath           string
+	KeyPath            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// TLSConfig build tls.Config from the options
+func (this *TLSOptions) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         this.ServerName,
+		InsecureSkipVerify: this.InsecureSkipVerify,
+	}
+	if this.CAPath != "" {
+		caData, err := ioutil.ReadFile(this.CAPath)
+		if err != nil {
+			return nil, fmt.Errorf("ReadFile CAPath:%s error:%w", this.CAPath, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no certificate found in CAPath:%s", this.CAPath)
+		}
+		config.RootCAs = pool
+	}
+	if this.CertPath != "" || this.KeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(this.CertPath, this.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("LoadX509KeyPair CertPath:%s KeyPath:%s error:%w", this.CertPath, this.KeyPath, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// SetTLS set the TLS options of the connections to nodes, the rpc addresses should be https://
+func (this *DnaClient) SetTLS(options *TLSOptions) error {
+	config, err := options.TLSConfig()
+	if err != nil {
+		return err
+	}
+	return this.SetTLSConfig(config)
+}
+
+// SetTLSConfig set the tls.Config of the connections to nodes
+func (this *DnaClient) SetTLSConfig(config *tls.Config) error {
+	transport, err := this.httpTransport()
+	if err != nil {
+		return err
+	}
+	transport.TLSClientConfig = config
+	transport.CloseIdleConnections()
+	return nil
+}
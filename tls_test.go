@@ -0,0 +1,237 @@
+package dnasdk
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testServerName = "dnanode.test"
+
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certPath string
+	keyPath  string
+}
+
+func (this *testCert) tlsCertificate(t *testing.T) tls.Certificate {
+	cert, err := tls.LoadX509KeyPair(this.certPath, this.keyPath)
+	if err != nil {
+		t.Fatalf("LoadX509KeyPair error:%s", err)
+	}
+	return cert
+}
+
+func (this *testCert) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(this.cert)
+	return pool
+}
+
+// newTestCert create a certificate signed by parent, self signed if parent is nil,
+// and write it and its key as PEM files in dir
+func newTestCert(t *testing.T, dir, name string, template *x509.Certificate, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey error:%s", err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatalf("rand.Int error:%s", err)
+	}
+	template.SerialNumber = serial
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate error:%s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate error:%s", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey error:%s", err)
+	}
+
+	c := &testCert{
+		cert:     cert,
+		key:      key,
+		certPath: filepath.Join(dir, name+".crt"),
+		keyPath:  filepath.Join(dir, name+".key"),
+	}
+	err = ioutil.WriteFile(c.certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile error:%s", err)
+	}
+	err = ioutil.WriteFile(c.keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile error:%s", err)
+	}
+	return c
+}
+
+type testPKI struct {
+	ca     *testCert
+	server *testCert
+	client *testCert
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	dir := t.TempDir()
+	ca := newTestCert(t, dir, "ca", &x509.Certificate{
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}, nil)
+	server := newTestCert(t, dir, "server", &x509.Certificate{
+		DNSNames:    []string{testServerName},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	client := newTestCert(t, dir, "client", &x509.Certificate{
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+	return &testPKI{ca: ca, server: server, client: client}
+}
+
+// newTestTLSServer start a node answering 7 to every request, with the server certificate of pki
+func newTestTLSServer(t *testing.T, pki *testPKI, clientAuth tls.ClientAuthType) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1","jsonrpc":"2.0","result":7}`))
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pki.server.tlsCertificate(t)},
+		ClientAuth:   clientAuth,
+		ClientCAs:    pki.ca.pool(),
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestTLSClient(t *testing.T, address string, options *TLSOptions) *DnaClient {
+	client, err := NewDnaClientWithOptions([]string{address},
+		WithTLS(options),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 1}),
+	)
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	return client
+}
+
+func TestTLSCustomCA(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newTestTLSServer(t, pki, tls.NoClientCert)
+
+	client := newTestTLSClient(t, server.URL, &TLSOptions{CAPath: pki.ca.certPath, ServerName: testServerName})
+	count, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount error:%s", err)
+	}
+	if count != 7 {
+		t.Fatalf("GetBlockCount:%d expected:7", count)
+	}
+
+	client = newTestTLSClient(t, server.URL, &TLSOptions{})
+	_, err = client.GetBlockCount()
+	var authorityErr x509.UnknownAuthorityError
+	if !errors.As(err, &authorityErr) {
+		t.Fatalf("GetBlockCount without the CA error:%v expected UnknownAuthorityError", err)
+	}
+}
+
+func TestTLSWrongServerName(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newTestTLSServer(t, pki, tls.NoClientCert)
+
+	client := newTestTLSClient(t, server.URL, &TLSOptions{CAPath: pki.ca.certPath, ServerName: "other.test"})
+	_, err := client.GetBlockCount()
+	var hostnameErr x509.HostnameError
+	if !errors.As(err, &hostnameErr) {
+		t.Fatalf("GetBlockCount with wrong ServerName error:%v expected HostnameError", err)
+	}
+}
+
+func TestTLSClientCert(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newTestTLSServer(t, pki, tls.RequireAndVerifyClientCert)
+
+	client := newTestTLSClient(t, server.URL, &TLSOptions{
+		CAPath:     pki.ca.certPath,
+		CertPath:   pki.client.certPath,
+		KeyPath:    pki.client.keyPath,
+		ServerName: testServerName,
+	})
+	count, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount error:%s", err)
+	}
+	if count != 7 {
+		t.Fatalf("GetBlockCount:%d expected:7", count)
+	}
+
+	client = newTestTLSClient(t, server.URL, &TLSOptions{CAPath: pki.ca.certPath, ServerName: testServerName})
+	_, err = client.GetBlockCount()
+	if err == nil {
+		t.Fatalf("GetBlockCount without client certificate should fail")
+	}
+}
+
+func TestTLSKeptByHttpClient(t *testing.T) {
+	pki := newTestPKI(t)
+	server := newTestTLSServer(t, pki, tls.NoClientCert)
+
+	client, err := NewDnaClientWithOptions([]string{server.URL},
+		WithTLS(&TLSOptions{CAPath: pki.ca.certPath, ServerName: testServerName}),
+		WithHttpClient(&http.Client{Timeout: time.Second * 5}),
+		WithRetryPolicy(&RetryPolicy{MaxAttempts: 1}),
+	)
+	if err != nil {
+		t.Fatalf("NewDnaClientWithOptions error:%s", err)
+	}
+	count, err := client.GetBlockCount()
+	if err != nil {
+		t.Fatalf("GetBlockCount with http client after TLS error:%s", err)
+	}
+	if count != 7 {
+		t.Fatalf("GetBlockCount:%d expected:7", count)
+	}
+}
+
+func TestTLSOptionsKeyPair(t *testing.T) {
+	pki := newTestPKI(t)
+	_, err := (&TLSOptions{CertPath: pki.client.certPath}).TLSConfig()
+	if err == nil {
+		t.Fatalf("TLSConfig with CertPath only should fail")
+	}
+	_, err = (&TLSOptions{CAPath: pki.client.keyPath}).TLSConfig()
+	if err == nil {
+		t.Fatalf("TLSConfig with a key as CAPath should fail")
+	}
+}
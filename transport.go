@@ -0,0 +1,300 @@
+package dnasdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RpcRequest is one call to DNA node, Method is one of DNA_RPC_* and Params are the JSON-RPC params
+type RpcRequest struct {
+	Method string
+	Params []interface{}
+	Qid    string
+}
+
+// RpcResponse is the answer of node. Error is the error returned by node for the call,
+// Result is the result data with the same format of the JSON-RPC result.
+// Latency is the time of the round trip, set by DnaClient.
+type RpcResponse struct {
+	Raw     []byte
+	Result  []byte
+	Error   error
+	Latency time.Duration
+}
+
+// Transport carries RpcRequest to the node at address. The returned error means the
+// node cannot be reached, and the next rpc address will be tried.
+type Transport interface {
+	RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error)
+}
+
+// BatchTransport is the Transport which can send several requests at once
+type BatchTransport interface {
+	Transport
+	BatchRoundTrip(ctx context.Context, address string, reqs []*RpcRequest) ([]*RpcResponse, error)
+}
+
+// JsonRpcTransport send request to the JSON-RPC port of node
+type JsonRpcTransport struct {
+	client *http.Client
+}
+
+func NewJsonRpcTransport(client *http.Client) *JsonRpcTransport {
+	return &JsonRpcTransport{client: client}
+}
+
+func (this *JsonRpcTransport) RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	data, err := this.Call(ctx, address, req.Method, req.Qid, req.Params)
+	if err != nil {
+		statusErr := &HTTPStatusError{}
+		if errors.As(err, &statusErr) && !statusErr.IsTransportFailure() {
+			return &RpcResponse{Raw: statusErr.Body, Error: statusErr}, nil
+		}
+		return nil, err
+	}
+	return this.parseResponse(req.Method, data), nil
+}
+
+// BatchRoundTrip send reqs in one JSON-RPC array request. The responses are
+// matched back by Qid and returned in the order of reqs.
+func (this *JsonRpcTransport) BatchRoundTrip(ctx context.Context, address string, reqs []*RpcRequest) ([]*RpcResponse, error) {
+	calls := make([]map[string]interface{}, 0, len(reqs))
+	for _, req := range reqs {
+		calls = append(calls, map[string]interface{}{
+			"method": req.Method,
+			"id":     req.Qid,
+			"params": req.Params,
+		})
+	}
+	data, err := json.Marshal(calls)
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal batch request error:%w", err)
+	}
+	data, err = this.post(ctx, address, data)
+	if err != nil {
+		statusErr := &HTTPStatusError{}
+		if !errors.As(err, &statusErr) || statusErr.IsTransportFailure() {
+			return nil, err
+		}
+		//node answer batch request with an error status, send them one by one
+		data = nil
+	}
+	data = bytes.TrimSpace(data)
+	ress := make([]*RpcResponse, len(reqs))
+	if len(data) == 0 || data[0] != '[' {
+		//node does not support batch request, send them one by one
+		for i, req := range reqs {
+			ress[i], err = this.RoundTrip(ctx, address, req)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return ress, nil
+	}
+	items := make([]json.RawMessage, 0, len(reqs))
+	err = json.Unmarshal(data, &items)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal batch response:%s error:%w", data, err)
+	}
+	index := make(map[string]int, len(reqs))
+	for i, req := range reqs {
+		index[req.Qid] = i
+	}
+	for _, item := range items {
+		res := &DNAJsonRpcRes{}
+		err = json.Unmarshal(item, res)
+		if err != nil {
+			return nil, fmt.Errorf("json.Unmarshal DNAJsonRpcRes:%s error:%w", item, err)
+		}
+		i, ok := index[res.Id]
+		if !ok || ress[i] != nil {
+			continue
+		}
+		ress[i] = this.parseResponse(reqs[i].Method, item)
+	}
+	for i, req := range reqs {
+		if ress[i] == nil {
+			ress[i] = &RpcResponse{Error: fmt.Errorf("Call %s id:%s no response in batch", req.Method, req.Qid)}
+		}
+	}
+	return ress, nil
+}
+
+func (this *JsonRpcTransport) parseResponse(method string, data []byte) *RpcResponse {
+	if data == nil {
+		return &RpcResponse{Error: fmt.Errorf("Call %s return nil.", method)}
+	}
+	res := &DNAJsonRpcRes{}
+	err := json.Unmarshal(data, res)
+	if err != nil {
+		return &RpcResponse{Raw: data, Error: fmt.Errorf("json.Unmarshal DNAJsonRpcRes:%s error:%w", data, err)}
+	}
+	result, err := res.HandleResult()
+	return &RpcResponse{Raw: data, Result: result, Error: err}
+}
+
+// Call sends RPC request to server and return the response body
+func (this *JsonRpcTransport) Call(ctx context.Context, address string, method string, id interface{}, params []interface{}) ([]byte, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"method": method,
+		"id":     id,
+		"params": params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return this.post(ctx, address, data)
+}
+
+func (this *JsonRpcTransport) post(ctx context.Context, address string, data []byte) ([]byte, error) {
+	req, err := http.NewRequest("POST", address, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := this.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: body}
+	}
+
+	return body, nil
+}
+
+// RestTransport send request to the REST port of node with the DNA_API_* endpoints.
+// The rpc addresses of DnaClient should be the REST addresses, like http://localhost:20334
+//
+// The REST API of DNA has no route for getunspendoutput, getversion and getidentityupdate,
+// so GetUnspendOutput, GetVersion and GetIdentityUpdate return ErrUnsupported. Transfers
+// need the unspent outputs, use JsonRpcTransport to build them. The health monitor only
+// check the block count of REST nodes.
+type RestTransport struct {
+	client *http.Client
+}
+
+func NewRestTransport(client *http.Client) *RestTransport {
+	return &RestTransport{client: client}
+}
+
+func (this *RestTransport) RoundTrip(ctx context.Context, address string, req *RpcRequest) (*RpcResponse, error) {
+	address = strings.TrimRight(address, "/")
+	switch req.Method {
+	case DNA_RPC_GETBLOCKCOUNT:
+		return this.get(ctx, address+DNA_API_GETBLOCKCOUNT)
+	case DNA_RPC_GETBLOCK:
+		if len(req.Params) != 1 {
+			return &RpcResponse{Error: ErrInvalidParameter}, nil
+		}
+		if hash, ok := req.Params[0].(string); ok {
+			return this.get(ctx, address+DNA_API_GETBLOCKBYHASH+"/"+hash)
+		}
+		return this.get(ctx, fmt.Sprintf("%s%s/%v", address, DNA_API_GETBLOCKBYHEIGHT, req.Params[0]))
+	case DNA_RPC_GETBLOCKHASH:
+		if len(req.Params) != 1 {
+			return &RpcResponse{Error: ErrInvalidParameter}, nil
+		}
+		return this.getBlockHash(ctx, address, req.Params[0])
+	case DNA_RPC_GETCURRENTBLOCKHASH:
+		res, err := this.get(ctx, address+DNA_API_GETBLOCKCOUNT)
+		if err != nil || res.Error != nil {
+			return res, err
+		}
+		count := uint32(0)
+		err = json.Unmarshal(res.Result, &count)
+		if err != nil || count == 0 {
+			return &RpcResponse{Raw: res.Raw, Error: fmt.Errorf("json.Unmarshal Count:%s error:%w", res.Result, err)}, nil
+		}
+		return this.getBlockHash(ctx, address, count-1)
+	case DNA_RPC_GETTRANSACTION:
+		if len(req.Params) != 1 {
+			return &RpcResponse{Error: ErrInvalidParameter}, nil
+		}
+		return this.get(ctx, fmt.Sprintf("%s%s/%v", address, DNA_API_GETTRANSACTION, req.Params[0]))
+	case DNA_RPC_GETASSET:
+		if len(req.Params) != 1 {
+			return &RpcResponse{Error: ErrInvalidParameter}, nil
+		}
+		return this.get(ctx, fmt.Sprintf("%s%s/%v", address, DNA_API_GETASSET, req.Params[0]))
+	case DNA_RPC_SENDTRANSACTION:
+		if len(req.Params) != 1 {
+			return &RpcResponse{Error: ErrInvalidParameter}, nil
+		}
+		data, err := json.Marshal(map[string]interface{}{
+			"Action":  "sendrawtransaction",
+			"Version": "1.0.0",
+			"Type":    "",
+			"Data":    req.Params[0],
+		})
+		if err != nil {
+			return &RpcResponse{Error: fmt.Errorf("json.Marshal sendrawtransaction error:%w", err)}, nil
+		}
+		return this.do(ctx, "POST", address+DNA_API_SENDTRANSACTION, data)
+	}
+	return &RpcResponse{Error: fmt.Errorf("%w method:%s", ErrUnsupported, req.Method)}, nil
+}
+
+func (this *RestTransport) getBlockHash(ctx context.Context, address string, height interface{}) (*RpcResponse, error) {
+	res, err := this.get(ctx, fmt.Sprintf("%s%s/%v", address, DNA_API_GETBLOCKBYHEIGHT, height))
+	if err != nil || res.Error != nil {
+		return res, err
+	}
+	blockInfo := &BlockInfo{}
+	err = json.Unmarshal(res.Result, blockInfo)
+	if err != nil {
+		return &RpcResponse{Raw: res.Raw, Error: fmt.Errorf("json.Unmarshal BlockInfo:%s error:%w", res.Result, err)}, nil
+	}
+	return &RpcResponse{Raw: res.Raw, Result: []byte(blockInfo.Hash)}, nil
+}
+
+func (this *RestTransport) get(ctx context.Context, url string) (*RpcResponse, error) {
+	return this.do(ctx, "GET", url, nil)
+}
+
+func (this *RestTransport) do(ctx context.Context, method, url string, data []byte) (*RpcResponse, error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := this.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: body}
+		if statusErr.IsTransportFailure() {
+			return nil, statusErr
+		}
+		return &RpcResponse{Raw: body, Error: statusErr}, nil
+	}
+	res := &DNARestRes{}
+	err = json.Unmarshal(body, res)
+	if err != nil {
+		return &RpcResponse{Raw: body, Error: fmt.Errorf("json.Unmarshal DNARestRes:%s error:%w", body, err)}, nil
+	}
+	result, err := res.HandleResult()
+	return &RpcResponse{Raw: body, Result: result, Error: err}, nil
+}
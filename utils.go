@@ -10,6 +10,7 @@ import (
 	"DNA/core/transaction"
 	txpl "DNA/core/transaction/payload"
 	"DNA/crypto"
+	"DNA/net/httpjsonrpc"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -17,16 +18,26 @@ import (
 )
 
 func ParseTransaction(txStr *Transactions) (*transaction.Transaction, error) {
+	return parseTransaction(txStr, false)
+}
+
+// ParseTransactionStrict is ParseTransaction, and check the hash of txStr is the hash of the
+// parsed transaction. IntegrityError is returned on mismatch.
+func ParseTransactionStrict(txStr *Transactions) (*transaction.Transaction, error) {
+	return parseTransaction(txStr, true)
+}
+
+func parseTransaction(txStr *Transactions, strict bool) (*transaction.Transaction, error) {
 	payload, err := ParseToPayload(txStr.TxType, []byte(txStr.Payload))
 	if err != nil {
-		return nil, fmt.Errorf("ParseToPayload:%s error:%s", txStr.Payload, err)
+		return nil, fmt.Errorf("ParseToPayload:%s error:%w", txStr.Payload, err)
 	}
 
 	attris := make([]*transaction.TxAttribute, len(txStr.Attributes))
 	for i, attr := range txStr.Attributes {
 		txAttr, err := ParseTransactionAttributes(&attr)
 		if err != nil {
-			return nil, fmt.Errorf("ParseTransactionAttributes:%+v error:%s", attr, err)
+			return nil, fmt.Errorf("ParseTransactionAttributes:%+v error:%w", attr, err)
 		}
 		attris[i] = txAttr
 	}
@@ -35,7 +46,7 @@ func ParseTransaction(txStr *Transactions) (*transaction.Transaction, error) {
 	for i, input := range txStr.UTXOInputs {
 		txInput, err := ParseTransactionUTXOTxInput(&input)
 		if err != nil {
-			return nil, fmt.Errorf("ParseTransactionUTXOTxInput:%+v error:%s", input, err)
+			return nil, fmt.Errorf("ParseTransactionUTXOTxInput:%+v error:%w", input, err)
 		}
 		utxoInputs[i] = txInput
 	}
@@ -44,7 +55,7 @@ func ParseTransaction(txStr *Transactions) (*transaction.Transaction, error) {
 	for i, input := range txStr.BalanceInputs {
 		txInput, err := ParseTransactionBalanceTxInputInfo(&input)
 		if err != nil {
-			return nil, fmt.Errorf("ParseTransactionBalanceTxInputInfo:%+v error:%s", input, err)
+			return nil, fmt.Errorf("ParseTransactionBalanceTxInputInfo:%+v error:%w", input, err)
 		}
 		balance[i] = txInput
 	}
@@ -53,7 +64,7 @@ func ParseTransaction(txStr *Transactions) (*transaction.Transaction, error) {
 	for i, output := range txStr.Outputs {
 		txOutput, err := ParseTransactionOutputs(&output)
 		if err != nil {
-			return nil, fmt.Errorf("ParseTransactionOutputs:%+v error:%s", output, err)
+			return nil, fmt.Errorf("ParseTransactionOutputs:%+v error:%w", output, err)
 		}
 		outputs[i] = txOutput
 	}
@@ -62,7 +73,7 @@ func ParseTransaction(txStr *Transactions) (*transaction.Transaction, error) {
 	for i, p := range txStr.Programs {
 		txProgram, err := ParseTransactionPrograms(&p)
 		if err != nil {
-			return nil, fmt.Errorf("ParseTransactionPrograms:%+v error:%s", p, err)
+			return nil, fmt.Errorf("ParseTransactionPrograms:%+v error:%w", p, err)
 		}
 		programs[i] = txProgram
 	}
@@ -73,7 +84,7 @@ func ParseTransaction(txStr *Transactions) (*transaction.Transaction, error) {
 		for i, output := range assetOutput.Txout {
 			txOutput, err := ParseTransactionOutputs(&output)
 			if err != nil {
-				return nil, fmt.Errorf("AssetOutputs ParseTransactionOutputs:%+v error:%s", output, err)
+				return nil, fmt.Errorf("AssetOutputs ParseTransactionOutputs:%+v error:%w", output, err)
 			}
 			outputs[i] = txOutput
 		}
@@ -105,59 +116,32 @@ func ParseTransaction(txStr *Transactions) (*transaction.Transaction, error) {
 
 	txHash, err := ParseUint256FromString(txStr.Hash)
 	if err != nil {
-		return nil, fmt.Errorf("Hash ParseUint256FromString:%s error:%s", txStr.Hash, err)
+		return nil, fmt.Errorf("Hash ParseUint256FromString:%s error:%w", txStr.Hash, err)
+	}
+	if strict {
+		//Hash compute from the content before SetHash
+		computed := tx.Hash()
+		if computed != txHash {
+			return nil, &IntegrityError{Object: "transaction", Hash: txHash, Computed: computed}
+		}
 	}
 	tx.SetHash(txHash)
 	return tx, nil
 }
 
+// ParseToPayload parse data with the PayloadParser registered for payloadType
 func ParseToPayload(payloadType transaction.TransactionType, data json.RawMessage) (transaction.Payload, error) {
-	var payload transaction.Payload
-
-	switch payloadType {
-	case transaction.RegisterAsset:
-		p := &PayloadRegisterAssetInfo{}
-		err := json.Unmarshal(data, p)
-		if err != nil {
-			return nil, fmt.Errorf("json.Unmarshal payload RegisterAssetInfo:%s error:%s", data, err)
-		}
-		regAsset, err := ParseRegisterAssetInfo(p)
-		if err != nil {
-			return nil, fmt.Errorf("ParsePayloadRegisterAssetInfo error:%s", err)
-		}
-		payload = regAsset
-	case transaction.Record:
-		p := &PayloadRecord{}
-		err := json.Unmarshal(data, p)
-		if err != nil {
-			return nil, fmt.Errorf("json.Unmarshal payload Record:%s error:%s", data, err)
-		}
-		record, err := ParseRecord(p)
-		if err != nil {
-			return nil, fmt.Errorf("ParsePayloadRecord error:%s", err)
-		}
-		payload = record
-	case transaction.DeployCode:
-		p := &PayloadDeployCodeInfo{}
-		err := json.Unmarshal(data, p)
-		if err != nil {
-			return nil, fmt.Errorf("json.Unmarshal payload DeployCodeInfo:%s error:%s", data, err)
-		}
-
-		deplyCode, err := ParseDeployCodeInfo(p)
-		if err != nil {
-			return nil, fmt.Errorf("ParsePayloadDeployCodeInfo error:%s", err)
-		}
-		payload = deplyCode
+	parser, ok := getPayloadParser(payloadType)
+	if !ok {
+		return nil, fmt.Errorf("%w TxType:%x", ErrUnknownTxType, payloadType)
 	}
-
-	return payload, nil
+	return parser(data)
 }
 
 func ParseTransactionAttributes(attr *TxAttributeInfo) (*transaction.TxAttribute, error) {
 	data, err := hex.DecodeString(attr.Data)
 	if err != nil {
-		return nil, fmt.Errorf("hex.DecodeString TxAttributeInfo.Data:%s error:%s", attr.Data, err)
+		return nil, fmt.Errorf("hex.DecodeString TxAttributeInfo.Data:%s error:%w", attr.Data, err)
 	}
 	txAttr := &transaction.TxAttribute{}
 	txAttr.Usage = transaction.TransactionAttributeUsage(attr.Usage)
@@ -168,7 +152,7 @@ func ParseTransactionAttributes(attr *TxAttributeInfo) (*transaction.TxAttribute
 func ParseTransactionUTXOTxInput(input *UTXOTxInputInfo) (*transaction.UTXOTxInput, error) {
 	txId, err := ParseUint256FromString(input.ReferTxID)
 	if err != nil {
-		return nil, fmt.Errorf("ParseUint256FromString UTXOTxInputInfo.ReferTxID:%s error:%s", input.ReferTxID, err)
+		return nil, fmt.Errorf("ParseUint256FromString UTXOTxInputInfo.ReferTxID:%s error:%w", input.ReferTxID, err)
 	}
 	return &transaction.UTXOTxInput{
 		ReferTxID:          txId,
@@ -179,11 +163,11 @@ func ParseTransactionUTXOTxInput(input *UTXOTxInputInfo) (*transaction.UTXOTxInp
 func ParseTransactionBalanceTxInputInfo(input *BalanceTxInputInfo) (*transaction.BalanceTxInput, error) {
 	assetId, err := ParseUint256FromString(input.AssetID)
 	if err != nil {
-		return nil, fmt.Errorf("ParseUint256FromString BalanceTxInputInfo.AssetID:%s error:%s", input.AssetID, err)
+		return nil, fmt.Errorf("ParseUint256FromString BalanceTxInputInfo.AssetID:%s error:%w", input.AssetID, err)
 	}
 	programHash, err := ParseUint160FromString(input.ProgramHash)
 	if err != nil {
-		return nil, fmt.Errorf("ParseUint160FromString BalanceTxInputInfo.ProgramHash:%s error:%s", input.ProgramHash, err)
+		return nil, fmt.Errorf("ParseUint160FromString BalanceTxInputInfo.ProgramHash:%s error:%w", input.ProgramHash, err)
 	}
 	return &transaction.BalanceTxInput{
 		AssetID:     assetId,
@@ -195,11 +179,11 @@ func ParseTransactionBalanceTxInputInfo(input *BalanceTxInputInfo) (*transaction
 func ParseTransactionOutputs(output *TxoutputInfo) (*transaction.TxOutput, error) {
 	assetId, err := ParseUint256FromString(output.AssetID)
 	if err != nil {
-		return nil, fmt.Errorf("ParseUint256FromString TxOutput.AssetID:%s error:%s", output.AssetID, err)
+		return nil, fmt.Errorf("ParseUint256FromString TxOutput.AssetID:%s error:%w", output.AssetID, err)
 	}
 	programHash, err := ParseUint160FromString(output.ProgramHash)
 	if err != nil {
-		return nil, fmt.Errorf("ParseUint160FromString TxOutput.ProgramHash:%s error:%s", output.ProgramHash, err)
+		return nil, fmt.Errorf("ParseUint160FromString TxOutput.ProgramHash:%s error:%w", output.ProgramHash, err)
 	}
 	return &transaction.TxOutput{
 		AssetID:     assetId,
@@ -211,11 +195,11 @@ func ParseTransactionOutputs(output *TxoutputInfo) (*transaction.TxOutput, error
 func ParseTransactionPrograms(p *ProgramInfo) (*program.Program, error) {
 	code, err := hex.DecodeString(p.Code)
 	if err != nil {
-		return nil, fmt.Errorf("hex.DecodeString Code:%s error:%s", p.Code, err)
+		return nil, fmt.Errorf("hex.DecodeString Code:%s error:%w", p.Code, err)
 	}
 	param, err := hex.DecodeString(p.Parameter)
 	if err != nil {
-		return nil, fmt.Errorf("hex.DecodeString Parameter:%s error:%s", p.Parameter, err)
+		return nil, fmt.Errorf("hex.DecodeString Parameter:%s error:%w", p.Parameter, err)
 	}
 	return &program.Program{
 		Code:      code,
@@ -230,27 +214,86 @@ func ParseRegisterAssetInfo(p *PayloadRegisterAssetInfo) (*txpl.RegisterAsset, e
 
 	controler, err := ParseUint160FromString(p.Controller)
 	if err != nil {
-		return nil, fmt.Errorf("Controller:%s ParseUint160FromString error:%s", p.Controller, err)
+		return nil, fmt.Errorf("Controller:%s ParseUint160FromString error:%w", p.Controller, err)
 	}
 	regAsset.Controller = controler
 
+	issuer, err := ParseIssuerInfo(&p.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("ParseIssuerInfo error:%w", err)
+	}
+	regAsset.Issuer = issuer
+	return regAsset, nil
+}
+
+func ParseIssuerInfo(p *httpjsonrpc.IssuerInfo) (*crypto.PubKey, error) {
 	x := &big.Int{}
-	_, err = fmt.Sscan(p.Issuer.X, x)
+	_, err := fmt.Sscan(p.X, x)
 	if err != nil {
-		return nil, fmt.Errorf("fmt.Sscan Issuer.X:%s error:%s", p.Issuer.X, err)
+		return nil, fmt.Errorf("fmt.Sscan Issuer.X:%s error:%w", p.X, err)
 	}
 	y := &big.Int{}
-	_, err = fmt.Sscan(p.Issuer.Y, y)
+	_, err = fmt.Sscan(p.Y, y)
 	if err != nil {
-		return nil, fmt.Errorf("fmt.Sscan Issuer.Y:%s error:%s", p.Issuer.Y, err)
+		return nil, fmt.Errorf("fmt.Sscan Issuer.Y:%s error:%w", p.Y, err)
 	}
-
-	issuer := &crypto.PubKey{
+	return &crypto.PubKey{
 		X: x,
 		Y: y,
+	}, nil
+}
+
+// ParseBookKeeperInfo parse the BookKeeper payload, Controller of node is the hex of Cert
+func ParseBookKeeperInfo(p *PayloadBookKeeperInfo) (*txpl.BookKeeper, error) {
+	pubKeyData, err := hex.DecodeString(p.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString PubKey:%s error:%w", p.PubKey, err)
 	}
-	regAsset.Issuer = issuer
-	return regAsset, nil
+	pubKey, err := crypto.DecodePoint(pubKeyData)
+	if err != nil {
+		return nil, fmt.Errorf("crypto.DecodePoint PubKey:%s error:%w", p.PubKey, err)
+	}
+	bookKeeper := &txpl.BookKeeper{}
+	bookKeeper.PubKey = pubKey
+	switch p.Action {
+	case "add":
+		bookKeeper.Action = txpl.BookKeeperAction_ADD
+	case "sub":
+		bookKeeper.Action = txpl.BookKeeperAction_SUB
+	default:
+		return nil, fmt.Errorf("unknown BookKeeper Action:%s", p.Action)
+	}
+	issuer, err := ParseIssuerInfo(&p.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("ParseIssuerInfo error:%w", err)
+	}
+	bookKeeper.Issuer = issuer
+	cert, err := hex.DecodeString(p.Controller)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString Controller:%s error:%w", p.Controller, err)
+	}
+	bookKeeper.Cert = cert
+	return bookKeeper, nil
+}
+
+func ParseIdentityUpdateInfo(p *PayloadIdentityUpdateInfo) (*txpl.IdentityUpdate, error) {
+	did, err := hex.DecodeString(p.DID)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString DID:%s error:%w", p.DID, err)
+	}
+	ddo, err := hex.DecodeString(p.DDO)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString DDO:%s error:%w", p.DDO, err)
+	}
+	updater, err := ParseIssuerInfo(&p.Updater)
+	if err != nil {
+		return nil, fmt.Errorf("ParseIssuerInfo error:%w", err)
+	}
+	return &txpl.IdentityUpdate{
+		DID:     did,
+		DDO:     ddo,
+		Updater: updater,
+	}, nil
 }
 
 func ParseRecord(p *PayloadRecord) (*txpl.Record, error) {
@@ -258,7 +301,7 @@ func ParseRecord(p *PayloadRecord) (*txpl.Record, error) {
 	record.RecordType = p.RecordType
 	data, err := hex.DecodeString(p.RecordData)
 	if err != nil {
-		return nil, fmt.Errorf("hex.DecodeString RecordData:%s error:%s", p.RecordData, err)
+		return nil, fmt.Errorf("hex.DecodeString RecordData:%s error:%w", p.RecordData, err)
 	}
 
 	record.RecordData = data
@@ -268,16 +311,16 @@ func ParseRecord(p *PayloadRecord) (*txpl.Record, error) {
 func ParseDeployCodeInfo(p *PayloadDeployCodeInfo) (*txpl.DeployCode, error) {
 	c, err := hex.DecodeString(p.Code.Code)
 	if err != nil {
-		return nil, fmt.Errorf("hex.DecodeString Code:%s error:%s", p.Code.Code, err)
+		return nil, fmt.Errorf("hex.DecodeString Code:%s error:%w", p.Code.Code, err)
 	}
 	paramByte, err := hex.DecodeString(p.Code.ParameterTypes)
 	if err != nil {
-		return nil, fmt.Errorf("hex.DecodeString ParameterTypes:%s error:%s", p.Code.ParameterTypes, err)
+		return nil, fmt.Errorf("hex.DecodeString ParameterTypes:%s error:%w", p.Code.ParameterTypes, err)
 	}
 	param := contract.ByteToContractParameterType(paramByte)
 	retByte, err := hex.DecodeString(p.Code.ReturnTypes)
 	if err != nil {
-		return nil, fmt.Errorf("hex.DecodeString ReturnTypes:%s error:%s", p.Code.ReturnTypes, err)
+		return nil, fmt.Errorf("hex.DecodeString ReturnTypes:%s error:%w", p.Code.ReturnTypes, err)
 	}
 	ret := contract.ByteToContractParameterType(retByte)
 
@@ -296,55 +339,96 @@ func ParseDeployCodeInfo(p *PayloadDeployCodeInfo) (*txpl.DeployCode, error) {
 }
 
 func ParseBlock(blockInfo *BlockInfo) (*ledger.Block, error) {
+	return parseBlock(blockInfo, false)
+}
+
+// ParseBlockStrict is ParseBlock, and check the hashes of block and of every transaction are
+// the hashes of the parsed content. IntegrityError is returned on mismatch.
+func ParseBlockStrict(blockInfo *BlockInfo) (*ledger.Block, error) {
+	return parseBlock(blockInfo, true)
+}
+
+func parseBlock(blockInfo *BlockInfo, strict bool) (*ledger.Block, error) {
 	txs := make([]*transaction.Transaction, len(blockInfo.Transactions))
 	for i, txStr := range blockInfo.Transactions {
-		tx, err := ParseTransaction(txStr)
+		tx, err := parseTransaction(txStr, strict)
 		if err != nil {
-			return nil, fmt.Errorf("ParseTransaction transactions:%s error:%s", txStr, err)
+			return nil, fmt.Errorf("ParseTransaction transactions:%s error:%w", txStr, err)
 		}
 		txs[i] = tx
 	}
 
-	program, err := ParseTransactionPrograms(&blockInfo.BlockData.Program)
+	blockHead, err := ParseBlockHead(blockInfo.BlockData)
+	if err != nil {
+		return nil, fmt.Errorf("ParseBlockHead error:%w", err)
+	}
+	if strict {
+		err = VerifyBlockHash(blockHead, blockInfo.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ledger.Block{
+		Blockdata:    blockHead,
+		Transactions: txs,
+	}, nil
+}
+
+// ParseBlockHead parse the header of block, transactions are not needed
+func ParseBlockHead(head *BlockHead) (*ledger.Blockdata, error) {
+	if head == nil {
+		return nil, fmt.Errorf("BlockData is nil")
+	}
+	program, err := ParseTransactionPrograms(&head.Program)
 	if err != nil {
-		return nil, fmt.Errorf("ParseTransactionPrograms Program:%s error:%s", blockInfo.BlockData.Program, err)
+		return nil, fmt.Errorf("ParseTransactionPrograms Program:%s error:%w", head.Program, err)
 	}
-	nextBookKeeper, err := ParseUint160FromString(blockInfo.BlockData.NextBookKeeper)
+	nextBookKeeper, err := ParseUint160FromString(head.NextBookKeeper)
 	if err != nil {
-		return nil, fmt.Errorf("ParseUint160FromString NextBookKeeper:%s error:%s", blockInfo.BlockData.NextBookKeeper, err)
+		return nil, fmt.Errorf("ParseUint160FromString NextBookKeeper:%s error:%w", head.NextBookKeeper, err)
 	}
-	prevBlockHash, err := ParseUint256FromString(blockInfo.BlockData.PrevBlockHash)
+	prevBlockHash, err := ParseUint256FromString(head.PrevBlockHash)
 	if err != nil {
-		return nil, fmt.Errorf("ParseUint256FromString PrevBlockHash:%s error:%s", blockInfo.BlockData.PrevBlockHash, err)
+		return nil, fmt.Errorf("ParseUint256FromString PrevBlockHash:%s error:%w", head.PrevBlockHash, err)
 	}
-	txRoot, err := ParseUint256FromString(blockInfo.BlockData.TransactionsRoot)
+	txRoot, err := ParseUint256FromString(head.TransactionsRoot)
 	if err != nil {
-		return nil, fmt.Errorf("ParseUint256FromString TransactionsRoot:%s error:%s", blockInfo.BlockData.TransactionsRoot, err)
+		return nil, fmt.Errorf("ParseUint256FromString TransactionsRoot:%s error:%w", head.TransactionsRoot, err)
 	}
 	blockHead := &ledger.Blockdata{}
 	blockHead.Program = program
 	blockHead.NextBookKeeper = nextBookKeeper
-	blockHead.Height = blockInfo.BlockData.Height
-	blockHead.Timestamp = blockInfo.BlockData.Timestamp
-	blockHead.Version = blockInfo.BlockData.Version
+	blockHead.Height = head.Height
+	blockHead.Timestamp = head.Timestamp
+	blockHead.Version = head.Version
 	blockHead.PrevBlockHash = prevBlockHash
-	blockHead.ConsensusData = blockInfo.BlockData.ConsensusData
+	blockHead.ConsensusData = head.ConsensusData
 	blockHead.TransactionsRoot = txRoot
+	return blockHead, nil
+}
 
-	return &ledger.Block{
-		Blockdata:    blockHead,
-		Transactions: txs,
-	}, nil
+// VerifyBlockHash check hash returned by node is the hash of header, IntegrityError is returned on mismatch
+func VerifyBlockHash(header *ledger.Blockdata, hash string) error {
+	blockHash, err := ParseUint256FromString(hash)
+	if err != nil {
+		return fmt.Errorf("Hash ParseUint256FromString:%s error:%w", hash, err)
+	}
+	computed := header.Hash()
+	if computed != blockHash {
+		return &IntegrityError{Object: "block", Hash: blockHash, Computed: computed}
+	}
+	return nil
 }
 
 func ParseUint160FromString(value string) (common.Uint160, error) {
 	data, err := hex.DecodeString(value)
 	if err != nil {
-		return common.Uint160{}, fmt.Errorf("hex.DecodeString error:%s", err)
+		return common.Uint160{}, fmt.Errorf("hex.DecodeString error:%w", err)
 	}
 	res, err := common.Uint160ParseFromBytes(data)
 	if err != nil {
-		return common.Uint160{}, fmt.Errorf("Uint160ParseFromBytes error:%s", err)
+		return common.Uint160{}, fmt.Errorf("Uint160ParseFromBytes error:%w", err)
 	}
 	return res, nil
 }
@@ -352,11 +436,11 @@ func ParseUint160FromString(value string) (common.Uint160, error) {
 func ParseUint256FromString(value string) (common.Uint256, error) {
 	data, err := hex.DecodeString(value)
 	if err != nil {
-		return common.Uint256{}, fmt.Errorf("hex.DecodeString error:%s", err)
+		return common.Uint256{}, fmt.Errorf("hex.DecodeString error:%w", err)
 	}
 	res, err := common.Uint256ParseFromBytes(data)
 	if err != nil {
-		return common.Uint256{}, fmt.Errorf("Uint160ParseFromBytes error:%s", err)
+		return common.Uint256{}, fmt.Errorf("Uint160ParseFromBytes error:%w", err)
 	}
 	return res, nil
 }
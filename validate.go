@@ -0,0 +1,147 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"DNA/core/ledger"
+	"DNA/core/transaction"
+	"DNA/crypto"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The checks of ValidateBlock, in BlockValidationError
+const (
+	BLOCK_CHECK_MERKLE_ROOT = "merkle root"
+	BLOCK_CHECK_PREV_HASH   = "prev hash"
+	BLOCK_CHECK_HEIGHT      = "height"
+	BLOCK_CHECK_TIMESTAMP   = "timestamp"
+)
+
+// DEFAULT_MAX_TIME_DRIFT is how far in the future of local time a block timestamp can be
+const DEFAULT_MAX_TIME_DRIFT = 10 * time.Minute
+
+// ErrBlockValidation is the cause of BlockValidationError
+var ErrBlockValidation = errors.New("block validation failed")
+
+// BlockValidationError is returned when the block at Height fails the Check
+type BlockValidationError struct {
+	Height uint32
+	Check  string
+	Detail string
+}
+
+func (this *BlockValidationError) Error() string {
+	return fmt.Sprintf("block height:%d %s check failed:%s", this.Height, this.Check, this.Detail)
+}
+
+func (this *BlockValidationError) Is(target error) bool {
+	return target == ErrBlockValidation
+}
+
+// ValidateBlock check block against its content and prev, the block before it. The transaction
+// hashes are computed from content, so the hashes returned by node are not trusted. With nil
+// prev, only the merkle root and the timestamp are checked.
+func ValidateBlock(block *ledger.Block, prev *ledger.Blockdata) error {
+	return validateBlock(block, prev, DEFAULT_MAX_TIME_DRIFT)
+}
+
+// BlockValidator validate a sequence of blocks from a trusted block, each block is checked
+// against the last valid one
+type BlockValidator struct {
+	// MaxTimeDrift is how far in the future of local time a block timestamp can be
+	MaxTimeDrift time.Duration
+	prev         *ledger.Blockdata
+}
+
+// NewBlockValidator return a BlockValidator following trusted, nil to trust the first block validated
+func NewBlockValidator(trusted *ledger.Blockdata) *BlockValidator {
+	return &BlockValidator{
+		MaxTimeDrift: DEFAULT_MAX_TIME_DRIFT,
+		prev:         trusted,
+	}
+}
+
+// Validate check block is the next of the last valid block, and keep it as the last on success
+func (this *BlockValidator) Validate(block *ledger.Block) error {
+	err := validateBlock(block, this.prev, this.MaxTimeDrift)
+	if err != nil {
+		return err
+	}
+	this.prev = block.Blockdata
+	return nil
+}
+
+// Last return the last valid block header
+func (this *BlockValidator) Last() *ledger.Blockdata {
+	return this.prev
+}
+
+func validateBlock(block *ledger.Block, prev *ledger.Blockdata, maxTimeDrift time.Duration) error {
+	if block == nil || block.Blockdata == nil {
+		return fmt.Errorf("%w: block is nil", ErrBlockValidation)
+	}
+	header := block.Blockdata
+	if len(block.Transactions) == 0 {
+		return &BlockValidationError{Height: header.Height, Check: BLOCK_CHECK_MERKLE_ROOT, Detail: "no transaction"}
+	}
+	txHashes := make([]Uint256, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		txHashes = append(txHashes, ComputeTransactionHash(tx))
+	}
+	root, err := crypto.ComputeRoot(txHashes)
+	if err != nil {
+		return fmt.Errorf("crypto.ComputeRoot error:%w", err)
+	}
+	if root != header.TransactionsRoot {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_MERKLE_ROOT,
+			Detail: fmt.Sprintf("TransactionsRoot:%x computed:%x", header.TransactionsRoot.ToArray(), root.ToArray()),
+		}
+	}
+
+	maxTime := time.Now().Add(maxTimeDrift).Unix()
+	if int64(header.Timestamp) > maxTime {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_TIMESTAMP,
+			Detail: fmt.Sprintf("Timestamp:%d is in the future", header.Timestamp),
+		}
+	}
+	if prev == nil {
+		return nil
+	}
+
+	if header.Height != prev.Height+1 {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_HEIGHT,
+			Detail: fmt.Sprintf("previous height:%d", prev.Height),
+		}
+	}
+	prevHash := prev.Hash()
+	if header.PrevBlockHash != prevHash {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_PREV_HASH,
+			Detail: fmt.Sprintf("PrevBlockHash:%x previous block hash:%x", header.PrevBlockHash.ToArray(), prevHash.ToArray()),
+		}
+	}
+	if header.Timestamp <= prev.Timestamp {
+		return &BlockValidationError{
+			Height: header.Height,
+			Check:  BLOCK_CHECK_TIMESTAMP,
+			Detail: fmt.Sprintf("Timestamp:%d not after previous timestamp:%d", header.Timestamp, prev.Timestamp),
+		}
+	}
+	return nil
+}
+
+// ComputeTransactionHash compute the hash of tx from its content as DNA does,
+// the hash set by SetHash is ignored
+func ComputeTransactionHash(tx *transaction.Transaction) Uint256 {
+	temp := sha256.Sum256(tx.GetMessage())
+	return Uint256(sha256.Sum256(temp[:]))
+}
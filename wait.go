@@ -0,0 +1,187 @@
+package dnasdk
+
+import (
+	. "DNA/common"
+	"DNA/core/ledger"
+	"DNA/core/transaction"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors returned by WaitForTransaction
+var (
+	// ErrTransactionTimeout: the transaction is in a block, but the confirmations are not reached in time
+	ErrTransactionTimeout = errors.New("wait for transaction confirmations timeout")
+	// ErrTransactionNotFound: the transaction is not in any block in time
+	ErrTransactionNotFound = errors.New("transaction not found in chain")
+	// ErrTransactionBlockNotFound: the transaction is returned by node, but is not in the WAIT_TX_MAX_DEPTH blocks on top
+	ErrTransactionBlockNotFound = errors.New("block of transaction not found")
+	// ErrTransactionRejected: an input of the transaction is spent by another transaction in chain
+	ErrTransactionRejected = errors.New("transaction rejected, input spent by another transaction")
+)
+
+const (
+	// WAIT_TX_POLL_INTERVAL is the interval WaitForTransaction check new blocks
+	WAIT_TX_POLL_INTERVAL = time.Second
+	// WAIT_TX_LOOKBACK is the count of blocks got one by one for a transaction already in chain,
+	// and the size of the batches of the blocks below
+	WAIT_TX_LOOKBACK = 100
+	// WAIT_TX_MAX_DEPTH is the count of blocks from the top scanned for a transaction already in chain
+	WAIT_TX_MAX_DEPTH = 10000
+)
+
+// WaitForTransaction wait until the transaction txHash is in a block with at least confirmations
+// blocks on top, the block itself included, and return the height and hash of the block.
+// Use the deadline of ctx as timeout. ErrTransactionNotFound or ErrTransactionTimeout is returned
+// when ctx is done, both wrap ctx.Err().
+// Only the hash is known, so a transaction rejected by node, or dropped from its pool, is not detected
+// and WaitForTransaction wait until ctx is done. Use WaitForSentTransaction for the transactions sent
+// by the client, it return ErrTransactionRejected.
+func (this *DnaClient) WaitForTransaction(ctx context.Context, txHash Uint256, confirmations uint32) (uint32, Uint256, error) {
+	return this.waitForTransaction(ctx, txHash, nil, confirmations)
+}
+
+// WaitForSentTransaction is WaitForTransaction of tx, ErrTransactionRejected is returned
+// when the inputs of tx are spent by another transaction
+func (this *DnaClient) WaitForSentTransaction(ctx context.Context, tx *transaction.Transaction, confirmations uint32) (uint32, Uint256, error) {
+	return this.waitForTransaction(ctx, tx.Hash(), tx.UTXOInputs, confirmations)
+}
+
+func (this *DnaClient) waitForTransaction(ctx context.Context, txHash Uint256, inputs []*transaction.UTXOTxInput, confirmations uint32) (uint32, Uint256, error) {
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	count, err := this.GetBlockCountContext(ctx)
+	if err != nil {
+		return 0, Uint256{}, fmt.Errorf("GetBlockCount error:%w", err)
+	}
+	//the blocks before next are scanned
+	next := count
+	found := false
+	var height uint32
+	var blockHash Uint256
+
+	_, err = this.GetTransactionContext(ctx, txHash)
+	switch {
+	case err == nil:
+		//the block of tx may be generated after count
+		count, err = this.GetBlockCountContext(ctx)
+		if err != nil {
+			return 0, Uint256{}, fmt.Errorf("GetBlockCount error:%w", err)
+		}
+		height, blockHash, err = this.findTransactionBlock(ctx, txHash, count)
+		if err != nil {
+			return 0, Uint256{}, err
+		}
+		found = true
+	case !errors.Is(err, ErrUnknownTransaction):
+		return 0, Uint256{}, fmt.Errorf("GetTransaction error:%w", err)
+	}
+
+	ticker := time.NewTicker(WAIT_TX_POLL_INTERVAL)
+	defer ticker.Stop()
+	for {
+		if found && count >= height+confirmations {
+			return height, blockHash, nil
+		}
+		select {
+		case <-ctx.Done():
+			if found {
+				return 0, Uint256{}, fmt.Errorf("%w height:%d: %w", ErrTransactionTimeout, height, ctx.Err())
+			}
+			return 0, Uint256{}, fmt.Errorf("%w: %w", ErrTransactionNotFound, ctx.Err())
+		case <-ticker.C:
+		}
+
+		curCount, err := this.GetBlockCountContext(ctx)
+		if err != nil {
+			continue
+		}
+		count = curCount
+		for !found && next < count {
+			block, err := this.GetBlockByHeightContext(ctx, next)
+			if err != nil {
+				break
+			}
+			if containsTransaction(block, txHash) {
+				found = true
+				height = next
+				blockHash = block.Blockdata.Hash()
+				break
+			}
+			if spendsInputs(block, inputs) {
+				return 0, Uint256{}, fmt.Errorf("%w height:%d", ErrTransactionRejected, next)
+			}
+			next++
+		}
+	}
+}
+
+// findTransactionBlock scan the blocks below count from the top for txHash, a transaction known
+// by node. The WAIT_TX_LOOKBACK blocks on top are got one by one, as a new transaction is usually
+// in them, the blocks below in batches of WAIT_TX_LOOKBACK, down to WAIT_TX_MAX_DEPTH blocks.
+func (this *DnaClient) findTransactionBlock(ctx context.Context, txHash Uint256, count uint32) (uint32, Uint256, error) {
+	bottom := uint32(0)
+	if count > WAIT_TX_MAX_DEPTH {
+		bottom = count - WAIT_TX_MAX_DEPTH
+	}
+	top := count
+	for i := 0; i < WAIT_TX_LOOKBACK && top > bottom; i++ {
+		top--
+		block, err := this.GetBlockByHeightContext(ctx, top)
+		if err != nil {
+			return 0, Uint256{}, fmt.Errorf("GetBlockByHeight height:%d error:%w", top, err)
+		}
+		if containsTransaction(block, txHash) {
+			return top, block.Blockdata.Hash(), nil
+		}
+	}
+	for top > bottom {
+		size := uint32(WAIT_TX_LOOKBACK)
+		if top-bottom < size {
+			size = top - bottom
+		}
+		heights := make([]uint32, size)
+		for i := range heights {
+			heights[i] = top - 1 - uint32(i)
+		}
+		blocks, errs, err := this.GetBlocksByHeightContext(ctx, heights)
+		if err != nil {
+			return 0, Uint256{}, fmt.Errorf("GetBlocksByHeight error:%w", err)
+		}
+		for i, block := range blocks {
+			if errs[i] != nil {
+				return 0, Uint256{}, fmt.Errorf("GetBlockByHeight height:%d error:%w", heights[i], errs[i])
+			}
+			if containsTransaction(block, txHash) {
+				return heights[i], block.Blockdata.Hash(), nil
+			}
+		}
+		top -= size
+	}
+	return 0, Uint256{}, fmt.Errorf("%w TxHash:%x block count:%d", ErrTransactionBlockNotFound, txHash.ToArray(), count)
+}
+
+func containsTransaction(block *ledger.Block, txHash Uint256) bool {
+	for _, tx := range block.Transactions {
+		if tx.Hash() == txHash {
+			return true
+		}
+	}
+	return false
+}
+
+func spendsInputs(block *ledger.Block, inputs []*transaction.UTXOTxInput) bool {
+	for _, tx := range block.Transactions {
+		for _, txInput := range tx.UTXOInputs {
+			for _, input := range inputs {
+				if txInput.ReferTxID == input.ReferTxID && txInput.ReferTxOutputIndex == input.ReferTxOutputIndex {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}